@@ -0,0 +1,35 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: annotation_type
+ * @Version: 1.0.0
+ * @Date: 2026/10/19 09:10
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package lib
+
+// Annotations stamped on every workload cops changes
+const (
+	AnnotationLastChangeID      = "cops.io/last-change-id"
+	AnnotationChangedBy         = "cops.io/changed-by"
+	AnnotationChangedAt         = "cops.io/changed-at"
+	AnnotationPreviousResources = "cops.io/previous-resources"
+)
+
+// PreviousResources is the snapshot of a workload before a change, kept in the
+// cops.io/previous-resources annotation
+type PreviousResources struct {
+	Replicas   int                  `json:"replicas"`
+	Containers []ContainerResources `json:"containers"`
+}
+
+// ContainerResources holds the limits and requests of one container
+type ContainerResources struct {
+	Name     string            `json:"name"`
+	Kind     string            `json:"kind"`
+	Limits   map[string]string `json:"limits,omitempty"`
+	Requests map[string]string `json:"requests,omitempty"`
+}
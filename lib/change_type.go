@@ -0,0 +1,95 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: change_type
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 11:05
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package lib
+
+// Column names of the change file header
+const (
+	ColumnCluster        = "cluster"
+	ColumnWorkload       = "workload"
+	ColumnContainersName = "containers_name"
+	ColumnWorkType       = "worktype"
+	ColumnNamespace      = "namespace"
+	ColumnReplicas       = "replicas"
+	ColumnLimitsCPU      = "limits_cpu"
+	ColumnLimitsMemory   = "limits_memory"
+	ColumnRequestsCPU    = "requests_cpu"
+	ColumnRequestsMemory = "requests_memory"
+	ColumnContainerKind  = "container_kind"
+	ColumnTargetQoS      = "target_qos"
+
+	// Any other named resource is set with a limits_<name> or requests_<name> column,
+	// e.g. limits_ephemeral-storage or requests_nvidia.com/gpu
+	ColumnLimitsPrefix   = "limits_"
+	ColumnRequestsPrefix = "requests_"
+)
+
+// Kinds of containers a row can target
+const (
+	ContainerKindContainer = "container"
+	ContainerKindInit      = "init"
+	ContainerKindSidecar   = "sidecar"
+)
+
+// RequiredColumns must be present in the header of every change file
+var RequiredColumns = []string{
+	ColumnWorkload,
+	ColumnContainersName,
+	ColumnWorkType,
+	ColumnNamespace,
+	ColumnReplicas,
+	ColumnLimitsCPU,
+	ColumnLimitsMemory,
+	ColumnRequestsCPU,
+	ColumnRequestsMemory,
+}
+
+// ChangeRow is a single row of the change file
+type ChangeRow struct {
+	Cluster        string
+	Workload       string
+	ContainerName  string
+	ContainerKind  string
+	WorkType       string
+	Namespace      string
+	Replicas       string
+	LimitsCPU      string
+	LimitsMemory   string
+	RequestsCPU    string
+	RequestsMemory string
+	OtherLimits    map[string]string
+	OtherRequests  map[string]string
+	TargetQoS      string
+	Line           int // line of the row in the change file
+}
+
+// ContainerChange is the container part of a change row
+type ContainerChange struct {
+	ContainerName  string
+	ContainerKind  string
+	LimitsCPU      string
+	LimitsMemory   string
+	RequestsCPU    string
+	RequestsMemory string
+	OtherLimits    map[string]string
+	OtherRequests  map[string]string
+	Line           int
+}
+
+// WorkloadChange combines all rows targeting the same workload, so that it is updated at once
+type WorkloadChange struct {
+	Workload   string
+	WorkType   string
+	Namespace  string
+	Replicas   string
+	TargetQoS  string
+	Containers []ContainerChange
+}
@@ -0,0 +1,25 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: checkpoint_type
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 20:15
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package lib
+
+import "time"
+
+// CheckpointEntry records a row of the change file that was applied to a workload
+type CheckpointEntry struct {
+	Cluster     string    `json:"cluster"`
+	Namespace   string    `json:"namespace"`
+	WorkType    string    `json:"workType"`
+	Workload    string    `json:"workload"`
+	Line        int       `json:"line"`
+	RecordHash  string    `json:"recordHash"` // detects rows that were edited since the checkpoint
+	CompletedAt time.Time `json:"completedAt"`
+}
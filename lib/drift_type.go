@@ -0,0 +1,38 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: drift_type
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 14:20
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package lib
+
+// Drift status of a container compared across two clusters
+const (
+	DriftStatusDrift   = "Drift"
+	DriftStatusInSync  = "In Sync"
+	DriftStatusMissing = "Missing"
+)
+
+// DriftInfo holds the sizing of the same container in the source and the target cluster
+type DriftInfo struct {
+	Workload           string
+	ContainerName      string
+	WorkType           string
+	Namespace          string
+	FromReplicas       int
+	ToReplicas         int
+	FromLimitsCPU      string
+	ToLimitsCPU        string
+	FromLimitsMemory   string
+	ToLimitsMemory     string
+	FromRequestsCPU    string
+	ToRequestsCPU      string
+	FromRequestsMemory string
+	ToRequestsMemory   string
+	DriftStatus        string
+}
@@ -0,0 +1,50 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: exit_code
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 19:40
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package lib
+
+// Process exit codes of an alter run
+const (
+	ExitSuccess    = 0 // every row succeeded, was unchanged or was skipped
+	ExitFailed     = 1 // no row succeeded
+	ExitPartial    = 2 // some rows succeeded and some failed
+	ExitValidation = 3 // the change file, its rows or the flags are invalid
+	ExitConnection = 4 // no cluster could be connected
+	ExitPermission = 5 // the RBAC pre-flight found a missing permission, nothing was changed
+
+	ExitInterrupted = 130 // the run was stopped by SIGINT/SIGTERM, resume it with --resume
+)
+
+// ExitCode derives the exit code of a run from the alter status of its rows
+func ExitCode(updates []ResourceInfo) int {
+	succeeded, failed, invalid := 0, 0, 0
+	for _, update := range updates {
+		switch update.AlterStatus {
+		case AlterStatusSuccess, AlterStatusUnchanged:
+			succeeded++
+		case AlterStatusFailed:
+			failed++
+		case AlterStatusInvalid:
+			invalid++
+		}
+	}
+
+	switch {
+	case failed+invalid == 0:
+		return ExitSuccess
+	case succeeded > 0:
+		return ExitPartial
+	case failed == 0:
+		return ExitValidation
+	default:
+		return ExitFailed
+	}
+}
@@ -0,0 +1,33 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: journal_type
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 21:05
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package lib
+
+import "time"
+
+var (
+	RunID       string // identifies the records of one alter run in the journal
+	Operator    string // user running cops
+	JournalPath string
+)
+
+// JournalRecord is the journal entry of one row of an alter run
+type JournalRecord struct {
+	RunID      string       `json:"runId"`
+	Operator   string       `json:"operator"`
+	Context    string       `json:"context"`
+	CSVPath    string       `json:"csvPath"`
+	StartedAt  time.Time    `json:"startedAt"`
+	FinishedAt time.Time    `json:"finishedAt"`
+	Outcome    string       `json:"outcome"`
+	ExitCode   int          `json:"exitCode"` // exit code of the run
+	Change     ResourceInfo `json:"change"`
+}
@@ -0,0 +1,37 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: label_type
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 17:05
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package lib
+
+// DefaultLabelKey is the pod template label that should hold the workload name
+const DefaultLabelKey = "app"
+
+// Label status of a workload pod template
+const (
+	LabelStatusConforming = "Conforming"
+	LabelStatusMissing    = "Missing"
+	LabelStatusMismatch   = "Mismatch"
+	LabelStatusFixed      = "Fixed"
+	LabelStatusSkipped    = "Skipped"
+	LabelStatusFailed     = "Failed"
+)
+
+// LabelInfo holds the label of a workload pod template compared with the workload name
+type LabelInfo struct {
+	Workload      string
+	WorkType      string
+	Namespace     string
+	LabelKey      string
+	CurrentValue  string
+	ExpectedValue string
+	LabelStatus   string
+	Reason        string
+}
@@ -0,0 +1,54 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: notify_type
+ * @Version: 1.0.0
+ * @Date: 2026/10/19 10:40
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package lib
+
+import "time"
+
+var (
+	NotifyURL     string
+	NotifyFormat  = NotifyFormatGeneric
+	NotifyTimeout = 10 * time.Second
+	NotifyRetries = 3
+)
+
+// Payload formats of the notification webhook
+const (
+	NotifyFormatGeneric  = "generic"
+	NotifyFormatSlack    = "slack"
+	NotifyFormatTeams    = "teams"
+	NotifyFormatDingTalk = "dingtalk"
+	NotifyFormatFeishu   = "feishu"
+)
+
+// NotifyFormats lists the supported webhook payload formats
+var NotifyFormats = []string{NotifyFormatGeneric, NotifyFormatSlack, NotifyFormatTeams, NotifyFormatDingTalk, NotifyFormatFeishu}
+
+// Events of a run sent to the notification webhook
+const (
+	NotifyEventStart  = "start"
+	NotifyEventFailed = "failed"
+	NotifyEventFinish = "finish"
+)
+
+// Notification is the generic webhook payload, the other formats carry its Text
+type Notification struct {
+	Event    string         `json:"event"`
+	RunID    string         `json:"runId"`
+	Operator string         `json:"operator"`
+	CSVPath  string         `json:"csvPath"`
+	Time     time.Time      `json:"time"`
+	Text     string         `json:"text"`
+	Rows     int            `json:"rows,omitempty"`     // rows of the change file, on start
+	Row      *ResourceInfo  `json:"row,omitempty"`      // the failed row
+	Summary  map[string]int `json:"summary,omitempty"`  // rows per alter status, on finish
+	ExitCode *int           `json:"exitCode,omitempty"` // exit code of the run, on finish
+}
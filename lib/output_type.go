@@ -0,0 +1,24 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: output_type
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 17:48
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package lib
+
+// Output formats of the result report
+const (
+	OutputTable    = "table"
+	OutputJSON     = "json"
+	OutputYAML     = "yaml"
+	OutputCSV      = "csv"
+	OutputMarkdown = "markdown"
+)
+
+// OutputFormats lists the supported output formats
+var OutputFormats = []string{OutputTable, OutputJSON, OutputYAML, OutputCSV, OutputMarkdown}
@@ -0,0 +1,51 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: permission_type
+ * @Version: 1.0.0
+ * @Date: 2026/10/19 11:30
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package lib
+
+// PermissionCheck is an action cops performs during an alter run or the labels command
+type PermissionCheck struct {
+	Group    string
+	Resource string
+	Verb     string
+	Required bool // without it no row can be applied, the others only lose a feature
+}
+
+// Name returns the column of the check in the permission matrix, e.g. "update deployments"
+func (c PermissionCheck) Name() string {
+	return c.Verb + " " + c.Resource
+}
+
+// PermissionColumns lists the actions of an alter run and the labels command in column order
+var PermissionColumns = []PermissionCheck{
+	{Group: "apps", Resource: "deployments", Verb: "get"},
+	{Group: "apps", Resource: "deployments", Verb: "list"},
+	{Group: "apps", Resource: "deployments", Verb: "update"},
+	{Group: "apps", Resource: "deployments", Verb: "patch"},
+	{Group: "apps", Resource: "statefulsets", Verb: "get"},
+	{Group: "apps", Resource: "statefulsets", Verb: "list"},
+	{Group: "apps", Resource: "statefulsets", Verb: "update"},
+	{Group: "apps", Resource: "statefulsets", Verb: "patch"},
+	{Group: "apps", Resource: "replicasets", Verb: "list"},
+	{Resource: "pods", Verb: "list"},
+	{Resource: "events", Verb: "create"},
+	{Resource: "resourcequotas", Verb: "list"},
+	{Resource: "namespaces", Verb: "list"},
+}
+
+// Permission is the result of a PermissionCheck in one namespace, an empty namespace is the whole cluster
+type Permission struct {
+	Cluster   string
+	Namespace string
+	Check     PermissionCheck
+	Allowed   bool
+	Reason    string
+}
@@ -11,29 +11,70 @@
 
 package lib
 
+import "time"
+
 var (
-	Version    = "1.0.0"
-	Kubeconfig string
-	CSVPath    string
+	Version      = "1.0.0"
+	Kubeconfig   string
+	KubeContext  string
+	KubeContexts []string
+	Namespace    string
+	CSVPath      string
+	Output       = OutputTable
+	ReportPath   string
+	Resume       bool
+	Preflight    = true
+)
+
+// Alter status of a row of the change file, every row ends up in the result with one of them
+const (
+	AlterStatusSuccess   = "Success"
+	AlterStatusUnchanged = "Unchanged" // the workload already has the values, nothing was written
+	AlterStatusFailed    = "Failed"
+	AlterStatusSkipped   = "Skipped"
+	AlterStatusInvalid   = "Invalid"
+)
+
+// AlterStatuses lists the alter statuses in the order of the summary
+var AlterStatuses = []string{AlterStatusSuccess, AlterStatusUnchanged, AlterStatusFailed, AlterStatusSkipped, AlterStatusInvalid}
+
+// Run status of a workload after the change
+const (
+	RunStatusAvailable        = "Available"
+	RunStatusPartialAvailable = "Partial Available"
+	RunStatusNotAvailable     = "Not Available"
 )
 
 type ResourceInfo struct {
-	DataTime              string
-	Workload              string
-	ContainerName         string
-	WorkType              string
-	Namespace             string
-	CurrentReplicas       int
-	AlterReplicas         int
-	CurrentLimitsCPU      string
-	AlterLimitsCPU        string
-	CurrentLimitsMemory   string
-	AlterLimitsMemory     string
-	CurrentRequestsCPU    string
-	AlterRequestsCPU      string
-	CurrentRequestsMemory string
-	AlterRequestsMemory   string
-	PodQos                string
-	RunStatus             string
-	AlterStatus           string
+	DataTime              time.Time        `json:"time"`
+	Line                  int              `json:"line"`
+	Cluster               string           `json:"cluster"`
+	Workload              string           `json:"workload"`
+	ContainerName         string           `json:"containerName"`
+	ContainerKind         string           `json:"containerKind"`
+	WorkType              string           `json:"workType"`
+	Namespace             string           `json:"namespace"`
+	CurrentReplicas       int              `json:"currentReplicas"`
+	AlterReplicas         int              `json:"alterReplicas"`
+	CurrentLimitsCPU      string           `json:"currentLimitsCPU"`
+	AlterLimitsCPU        string           `json:"alterLimitsCPU"`
+	CurrentLimitsMemory   string           `json:"currentLimitsMemory"`
+	AlterLimitsMemory     string           `json:"alterLimitsMemory"`
+	CurrentRequestsCPU    string           `json:"currentRequestsCPU"`
+	AlterRequestsCPU      string           `json:"alterRequestsCPU"`
+	CurrentRequestsMemory string           `json:"currentRequestsMemory"`
+	AlterRequestsMemory   string           `json:"alterRequestsMemory"`
+	OtherResources        []ResourceChange `json:"otherResources,omitempty"`
+	CurrentPodQos         string           `json:"currentPodQos"`
+	PodQos                string           `json:"podQos"`
+	RunStatus             string           `json:"runStatus"`
+	AlterStatus           string           `json:"alterStatus"`
+	Reason                string           `json:"reason,omitempty"`
+}
+
+// ResourceChange holds the before and after value of a named resource other than CPU and memory
+type ResourceChange struct {
+	Name    string `json:"name"` // e.g. limits.ephemeral-storage or requests.nvidia.com/gpu
+	Current string `json:"current"`
+	Alter   string `json:"alter"`
 }
@@ -0,0 +1,86 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: drift_mode
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 15:40
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package mode
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	AlterResource "github.com/Einic/cops/resources"
+	"github.com/Einic/cops/table"
+	"github.com/Einic/cops/utils"
+	"github.com/Einic/cops/zaplog"
+	"go.uber.org/zap"
+	"os"
+)
+
+// DriftMode compares the sizing of same-named workloads across two clusters
+func DriftMode(ctx context.Context, logger zaplog.Logger, args []string) {
+	flags := flag.NewFlagSet("drift", flag.ExitOnError)
+	kubeconfigFlag := flags.String("kubeconfig", "", "Path to the kubeconfig file")
+	fromFlag := flags.String("from", "", "The kubeconfig context of the source cluster")
+	toFlag := flags.String("to", "", "The kubeconfig context of the target cluster")
+	namespaceFlag := flags.String("n", "", "Only compare workloads in this namespace")
+	namespaceLongFlag := flags.String("namespace", "", "Only compare workloads in this namespace")
+	csvFlag := flags.String("csv", "", "Write a change file that aligns the target cluster with the source")
+	allFlag := flags.Bool("all", false, "Also list containers that are in sync")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s drift --from <context> --to <context> [options]\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Options:")
+		fmt.Printf("      --from        The kubeconfig context of the source cluster.\n")
+		fmt.Printf("      --to          The kubeconfig context of the target cluster.\n")
+		fmt.Printf("      --kubeconfig  Path to the kubeconfig file, defaults to $KUBECONFIG or ~/.kube/config.\n")
+		fmt.Printf("  -n, --namespace   Only compare workloads in this namespace, defaults to all namespaces.\n")
+		fmt.Printf("      --csv         Write a change file that aligns the target cluster with the source [--csv ./align.csv].\n")
+		fmt.Printf("      --all         Also list containers that are in sync.\n")
+	}
+
+	_ = flags.Parse(args)
+
+	if *fromFlag == "" || *toFlag == "" {
+		logger.Warn("Both --from and --to contexts are required for drift")
+		flags.Usage()
+		os.Exit(lib.ExitValidation)
+	}
+
+	fromCluster, err := utils.NewKubeCluster(*kubeconfigFlag, *fromFlag, "")
+	if err != nil {
+		logger.Error("Error creating clientset", zap.String("Context", *fromFlag), zap.Error(err))
+		os.Exit(lib.ExitConnection)
+	}
+
+	toCluster, err := utils.NewKubeCluster(*kubeconfigFlag, *toFlag, "")
+	if err != nil {
+		logger.Error("Error creating clientset", zap.String("Context", *toFlag), zap.Error(err))
+		os.Exit(lib.ExitConnection)
+	}
+
+	namespace := firstNonEmpty(*namespaceFlag, *namespaceLongFlag)
+	drifts, err := AlterResource.CompareWorkloads(ctx, fromCluster.Clientset, toCluster.Clientset, namespace, *allFlag, logger)
+	if err != nil {
+		logger.Error("Error comparing workloads", zap.String("From", fromCluster.Name), zap.String("To", toCluster.Name), zap.Error(err))
+		os.Exit(lib.ExitFailed)
+	}
+
+	table.PrintDriftTable(drifts, fromCluster.Name, toCluster.Name)
+
+	if *csvFlag != "" {
+		written, err := utils.WriteDriftCSV(*csvFlag, drifts, toCluster.Name, logger)
+		if err != nil {
+			logger.Error("Error writing drift CSV file", zap.String("Path", *csvFlag), zap.Error(err))
+			os.Exit(lib.ExitFailed)
+		}
+		logger.Info("Drift change file written", zap.String("Path", *csvFlag), zap.Int("Rows", written))
+	}
+}
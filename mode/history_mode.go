@@ -0,0 +1,181 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: history_mode
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 21:30
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package mode
+
+import (
+	"flag"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	"github.com/Einic/cops/table"
+	"github.com/Einic/cops/utils"
+	"github.com/Einic/cops/zaplog"
+	"go.uber.org/zap"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// HistoryMode lists the journal records of earlier runs matching the filters
+func HistoryMode(logger zaplog.Logger, args []string) {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	journalFlag := flags.String("journal", utils.DefaultJournalPath(), "Path to the change journal")
+	workloadFlag := flags.String("w", "", "Only list changes of workloads matching this name or glob")
+	workloadLongFlag := flags.String("workload", "", "Only list changes of workloads matching this name or glob")
+	namespaceFlag := flags.String("n", "", "Only list changes in namespaces matching this name or glob")
+	namespaceLongFlag := flags.String("namespace", "", "Only list changes in namespaces matching this name or glob")
+	contextFlag := flags.String("context", "", "Only list changes of this kubeconfig context")
+	sinceFlag := flags.String("since", "", "Only list runs started at or after this date")
+	untilFlag := flags.String("until", "", "Only list runs started at or before this date")
+	limitFlag := flags.Int("limit", 0, "Only list the most recent changes")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s history [options]\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Options:")
+		fmt.Printf("  -w, --workload    Only list changes of workloads matching this name or glob.\n")
+		fmt.Printf("  -n, --namespace   Only list changes in namespaces matching this name or glob.\n")
+		fmt.Printf("      --context     Only list changes of this kubeconfig context.\n")
+		fmt.Printf("      --since       Only list runs started at or after this date [--since 2026-10-01].\n")
+		fmt.Printf("      --until       Only list runs started at or before this date [--until 2026-10-18].\n")
+		fmt.Printf("      --limit       Only list the most recent changes.\n")
+		fmt.Printf("      --journal     Path to the change journal, defaults to ~/.cops/journal.jsonl.\n")
+	}
+
+	_ = flags.Parse(args)
+
+	since, err := parseJournalTime(*sinceFlag, false)
+	if err != nil {
+		logger.Error("Invalid --since", zap.Error(err))
+		os.Exit(lib.ExitValidation)
+	}
+	until, err := parseJournalTime(*untilFlag, true)
+	if err != nil {
+		logger.Error("Invalid --until", zap.Error(err))
+		os.Exit(lib.ExitValidation)
+	}
+
+	records, err := utils.ReadJournal(*journalFlag)
+	if err != nil {
+		logger.Error("Error reading the change journal", zap.String("Path", *journalFlag), zap.Error(err))
+		os.Exit(lib.ExitFailed)
+	}
+
+	workload := firstNonEmpty(*workloadFlag, *workloadLongFlag)
+	namespace := firstNonEmpty(*namespaceFlag, *namespaceLongFlag)
+	var matched []lib.JournalRecord
+	for _, record := range records {
+		switch {
+		case !matchFilter(workload, record.Change.Workload),
+			!matchFilter(namespace, record.Change.Namespace),
+			*contextFlag != "" && record.Context != *contextFlag,
+			!since.IsZero() && record.StartedAt.Before(since),
+			!until.IsZero() && record.StartedAt.After(until):
+			continue
+		}
+		matched = append(matched, record)
+	}
+
+	if *limitFlag > 0 && len(matched) > *limitFlag {
+		matched = matched[len(matched)-*limitFlag:]
+	}
+
+	table.PrintHistoryTable(matched)
+}
+
+// ShowMode prints every row of one run of the journal
+func ShowMode(logger zaplog.Logger, args []string) {
+	flags := flag.NewFlagSet("show", flag.ExitOnError)
+	journalFlag := flags.String("journal", utils.DefaultJournalPath(), "Path to the change journal")
+	outputFlag := flags.String("o", "", "Output format of the run")
+	outputLongFlag := flags.String("output", "", "Output format of the run")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s show <run-id> [options]\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Options:")
+		fmt.Printf("  -o, --output      Output format of the run: table, json, yaml, csv or markdown.\n")
+		fmt.Printf("      --journal     Path to the change journal, defaults to ~/.cops/journal.jsonl.\n")
+	}
+
+	// The run id comes first, the flags follow it
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		flags.Usage()
+		os.Exit(lib.ExitValidation)
+	}
+	runID := args[0]
+	_ = flags.Parse(args[1:])
+
+	records, err := utils.ReadJournal(*journalFlag)
+	if err != nil {
+		logger.Error("Error reading the change journal", zap.String("Path", *journalFlag), zap.Error(err))
+		os.Exit(lib.ExitFailed)
+	}
+
+	var run []lib.JournalRecord
+	var changes []lib.ResourceInfo
+	for _, record := range records {
+		if record.RunID == runID {
+			run = append(run, record)
+			changes = append(changes, record.Change)
+		}
+	}
+	if len(run) == 0 {
+		logger.Error("Run not found in the change journal", zap.String("RunID", runID), zap.String("Path", *journalFlag))
+		os.Exit(lib.ExitFailed)
+	}
+
+	output := firstNonEmpty(*outputFlag, *outputLongFlag, lib.OutputTable)
+	if output == lib.OutputTable {
+		first := run[0]
+		fmt.Printf("Run:       %s\n", first.RunID)
+		fmt.Printf("Operator:  %s\n", first.Operator)
+		fmt.Printf("File:      %s\n", first.CSVPath)
+		fmt.Printf("Started:   %s\n", first.StartedAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("Finished:  %s\n", first.FinishedAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("Exit code: %d\n", first.ExitCode)
+	}
+
+	if err := table.PrintUpdates(changes, output); err != nil {
+		logger.Error("Error writing the run", zap.Error(err))
+		os.Exit(lib.ExitValidation)
+	}
+}
+
+// matchFilter matches a value against a name or glob, an empty filter matches everything
+func matchFilter(filter, value string) bool {
+	if filter == "" {
+		return true
+	}
+	matched, err := path.Match(filter, value)
+	return err == nil && matched
+}
+
+// parseJournalTime parses a date or a RFC3339 time in local time. A date used as the end of
+// a range covers the whole day.
+func parseJournalTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return t, fmt.Errorf("expected a date like 2006-01-02 or a RFC3339 time, got %q", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
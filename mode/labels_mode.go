@@ -0,0 +1,130 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: labels_mode
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 17:32
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package mode
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	AlterResource "github.com/Einic/cops/resources"
+	"github.com/Einic/cops/table"
+	"github.com/Einic/cops/utils"
+	"github.com/Einic/cops/zaplog"
+	"go.uber.org/zap"
+	"os"
+)
+
+// LabelsMode audits the workload name label of Deployment/StatefulSet pod templates. Templates are only
+// fixed with --fix, which needs a namespace or --all-namespaces because every fix causes a rollout.
+func LabelsMode(ctx context.Context, logger zaplog.Logger, args []string) {
+	flags := flag.NewFlagSet("labels", flag.ExitOnError)
+	kubeconfigFlag := flags.String("kubeconfig", "", "Path to the kubeconfig file")
+	contextFlag := flags.String("context", "", "The kubeconfig context to use")
+	namespaceFlag := flags.String("n", "", "Only check workloads in this namespace")
+	namespaceLongFlag := flags.String("namespace", "", "Only check workloads in this namespace")
+	labelKeyFlag := flags.String("label-key", lib.DefaultLabelKey, "The pod template label that should hold the workload name")
+	fixFlag := flags.Bool("fix", false, "Patch the pod template of non-conforming workloads")
+	allNamespacesFlag := flags.Bool("all-namespaces", false, "Check workloads in all namespaces")
+	allFlag := flags.Bool("all", false, "Also list conforming workloads")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s labels [options]\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Options:")
+		fmt.Printf("      --label-key   The pod template label that should hold the workload name, defaults to app [--label-key app.kubernetes.io/name].\n")
+		fmt.Printf("      --fix         Patch the pod template of non-conforming workloads, which rolls them out. Without it nothing is changed.\n")
+		fmt.Printf("      --all         Also list conforming workloads.\n")
+		fmt.Printf("      --kubeconfig  Path to the kubeconfig file, defaults to $KUBECONFIG, ~/.kube/config or in-cluster config.\n")
+		fmt.Printf("      --context     The kubeconfig context to use, defaults to the current context.\n")
+		fmt.Printf("  -n, --namespace   Only check workloads in this namespace, an audit defaults to all namespaces.\n")
+		fmt.Printf("      --all-namespaces  Check workloads in all namespaces, --fix needs it or a namespace.\n")
+	}
+
+	_ = flags.Parse(args)
+
+	if *labelKeyFlag == "" {
+		logger.Warn("The --label-key must not be empty")
+		flags.Usage()
+		os.Exit(lib.ExitValidation)
+	}
+
+	// Fixing every namespace of the cluster rolls out all of its non-conforming workloads, so it must be asked for
+	namespace := firstNonEmpty(*namespaceFlag, *namespaceLongFlag)
+	if namespace != "" && *allNamespacesFlag {
+		logger.Warn("Use either --namespace or --all-namespaces")
+		flags.Usage()
+		os.Exit(lib.ExitValidation)
+	}
+	if *fixFlag && namespace == "" && !*allNamespacesFlag {
+		logger.Warn("The --fix flag needs --namespace or --all-namespaces")
+		flags.Usage()
+		os.Exit(lib.ExitValidation)
+	}
+
+	cluster, err := utils.NewKubeCluster(*kubeconfigFlag, *contextFlag, "")
+	if err != nil {
+		logger.Error("Error creating clientset", zap.String("Context", *contextFlag), zap.Error(err))
+		os.Exit(lib.ExitConnection)
+	}
+
+	// The labels command lists the workloads of the namespace, or of the whole cluster, and patches them with --fix
+	permissions, err := AlterResource.CheckPermissions(ctx, cluster.Clientset, namespace, utils.LabelPermissionChecks(*fixFlag))
+	if err != nil {
+		logger.Error("Error checking permissions", zap.String("Cluster", cluster.Name), zap.Error(err))
+		os.Exit(lib.ExitFailed)
+	}
+	for i := range permissions {
+		permissions[i].Cluster = cluster.Name
+	}
+	table.PrintPermissionTable(permissions)
+	if missing := utils.MissingPermissions(permissions); len(missing) > 0 {
+		for _, permission := range missing {
+			logger.Error("Missing permission, no workload was checked", zap.String("Cluster", permission.Cluster), zap.String("Namespace", permission.Namespace), zap.String("Permission", permission.Check.Name()), zap.String("Reason", permission.Reason))
+		}
+		os.Exit(lib.ExitPermission)
+	}
+
+	labels, err := AlterResource.AuditWorkloadLabels(ctx, cluster.Clientset, namespace, *labelKeyFlag, *allFlag)
+	if err != nil {
+		logger.Error("Error auditing workload labels", zap.String("Cluster", cluster.Name), zap.Error(err))
+		os.Exit(lib.ExitFailed)
+	}
+
+	if *fixFlag {
+		labels = AlterResource.FixWorkloadLabels(ctx, cluster.Clientset, labels, logger)
+	}
+
+	table.PrintLabelTable(labels)
+	os.Exit(labelsExitCode(labels))
+}
+
+// labelsExitCode fails the command when a fix failed, partially when other workloads were fixed
+func labelsExitCode(labels []lib.LabelInfo) int {
+	fixed, failed := 0, 0
+	for _, label := range labels {
+		switch label.LabelStatus {
+		case lib.LabelStatusFixed:
+			fixed++
+		case lib.LabelStatusFailed:
+			failed++
+		}
+	}
+
+	switch {
+	case failed == 0:
+		return lib.ExitSuccess
+	case fixed > 0:
+		return lib.ExitPartial
+	default:
+		return lib.ExitFailed
+	}
+}
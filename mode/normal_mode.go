@@ -12,20 +12,42 @@
 package mode
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/Einic/cops/lib"
+	"github.com/Einic/cops/notify"
+	"github.com/Einic/cops/report"
+	AlterResource "github.com/Einic/cops/resources"
 	"github.com/Einic/cops/table"
 	"github.com/Einic/cops/utils"
 	"github.com/Einic/cops/zaplog"
 	"go.uber.org/zap"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
 	"os"
-	"strconv"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 )
 
+// workloadUpdateTimeout bounds the update of a workload, which keeps running after an interrupt
+const workloadUpdateTimeout = 2 * time.Minute
+
+// errInterrupted is the reason of the rows that were not applied because the run was stopped
+var errInterrupted = errors.New("interrupted before the row was applied, re-run with --resume")
+
 func NormalMode(logger zaplog.Logger) {
+	// SIGINT/SIGTERM stop the run after the workloads that are being updated. The handler is released after
+	// the first signal, so that a second one terminates the process when an update hangs.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	// Define flags
 	versionFlag := flag.Bool("v", false, "Print version number and MD5 hash")
 	versionLongFlag := flag.Bool("version", false, "Print version number and MD5 hash")
@@ -33,13 +55,63 @@ func NormalMode(logger zaplog.Logger) {
 	helpLongFlag := flag.Bool("help", false, "Show help message")
 	alterFlag := flag.String("a", "", "Please alter resource")
 	alterLongFlag := flag.String("alter", "", "Please alter resource")
+	kubeconfigFlag := flag.String("kubeconfig", "", "Path to the kubeconfig file")
+	contextFlag := flag.String("context", "", "The kubeconfig context to use")
+	namespaceFlag := flag.String("n", "", "Default namespace for rows without one")
+	namespaceLongFlag := flag.String("namespace", "", "Default namespace for rows without one")
+	contextsFlag := flag.String("contexts", "", "Comma-separated kubeconfig contexts to apply every row to")
+	outputFlag := flag.String("o", "", "Output format of the result report")
+	outputLongFlag := flag.String("output", "", "Output format of the result report")
+	reportFlag := flag.String("report", "", "Write a self-contained HTML report of the run")
+	resumeFlag := flag.Bool("resume", false, "Skip the rows applied by an earlier run of the same change file")
+	journalFlag := flag.String("journal", utils.DefaultJournalPath(), "Path to the change journal")
+	noPreflightFlag := flag.Bool("no-preflight", false, "Skip the RBAC pre-flight")
+	notifyURLFlag := flag.String("notify-url", "", "Webhook to notify at the start, on failed rows and at the end of a run")
+	notifyFormatFlag := flag.String("notify-format", lib.NotifyFormatGeneric, "Payload format of the notification webhook")
+	notifyTimeoutFlag := flag.Duration("notify-timeout", lib.NotifyTimeout, "Timeout of a notification request")
+	notifyRetriesFlag := flag.Int("notify-retries", lib.NotifyRetries, "Retries of a failed notification request")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s drift --from <context> --to <context> [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s labels [--fix -n <namespace> | --all-namespaces] [--label-key <key>] [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s history [-w <workload>] [-n <namespace>] [--since <date>] [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s show <run-id> [options]\n", os.Args[0])
 		fmt.Fprintln(os.Stderr, "Options:")
 		fmt.Printf("  -v, --version   Print version number and MD5 hash.\n")
 		fmt.Printf("  -h, --help      Please read README.md to configure.\n")
-		fmt.Printf("  -a, --alter     Please alter resource [-a /root/.kube/config ./example.csv].\n")
+		fmt.Printf("  -a, --alter     Please alter resource [-a ./example.csv] or [-a /root/.kube/config ./example.csv].\n")
+		fmt.Printf("      --kubeconfig  Path to the kubeconfig file, defaults to $KUBECONFIG, ~/.kube/config or in-cluster config.\n")
+		fmt.Printf("      --context     The kubeconfig context to use, defaults to the current context.\n")
+		fmt.Printf("      --contexts    Comma-separated contexts, rows without a cluster column are applied to each of them.\n")
+		fmt.Printf("  -n, --namespace   Default namespace for rows with an empty namespace column.\n")
+		fmt.Printf("  -o, --output      Output format of the result report: table, json, yaml, csv or markdown.\n")
+		fmt.Printf("      --report      Write a self-contained HTML report of the run [--report ./report.html].\n")
+		fmt.Printf("      --resume      Skip the rows applied by an earlier, interrupted run of the same change file.\n")
+		fmt.Printf("      --journal     Path to the change journal, defaults to ~/.cops/journal.jsonl.\n")
+		fmt.Printf("      --no-preflight    Skip the RBAC pre-flight that checks the permissions before any row is applied.\n")
+		fmt.Printf("      --notify-url      Webhook notified at the start, on every failed row and at the end of a run.\n")
+		fmt.Printf("      --notify-format   Webhook payload: generic, slack, teams, dingtalk or feishu, defaults to generic.\n")
+		fmt.Printf("      --notify-timeout  Timeout of a notification request, defaults to 10s.\n")
+		fmt.Printf("      --notify-retries  Retries of a failed notification request, defaults to 3.\n")
+	}
+
+	// Subcommands have their own flags
+	if len(os.Args) > 1 && os.Args[1] == "drift" {
+		DriftMode(ctx, logger, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "labels" {
+		LabelsMode(ctx, logger, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "history" {
+		HistoryMode(logger, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "show" {
+		ShowMode(logger, os.Args[2:])
+		return
 	}
 
 	// Parse flags
@@ -51,8 +123,25 @@ func NormalMode(logger zaplog.Logger) {
 	} else if *helpFlag || *helpLongFlag {
 		flag.Usage()
 	} else if *alterFlag != "" || *alterLongFlag != "" {
-		args := os.Args[2:]
-		executeCommand(logger, args...)
+		lib.Kubeconfig = *kubeconfigFlag
+		lib.KubeContext = *contextFlag
+		lib.Namespace = firstNonEmpty(*namespaceFlag, *namespaceLongFlag)
+		lib.KubeContexts = splitList(*contextsFlag)
+		lib.ReportPath = *reportFlag
+		lib.Resume = *resumeFlag
+		lib.JournalPath = *journalFlag
+		lib.Preflight = !*noPreflightFlag
+		lib.NotifyURL = *notifyURLFlag
+		lib.NotifyFormat = *notifyFormatFlag
+		lib.NotifyTimeout = *notifyTimeoutFlag
+		lib.NotifyRetries = *notifyRetriesFlag
+		lib.Output = firstNonEmpty(*outputFlag, *outputLongFlag, lib.OutputTable)
+		if !slices.Contains(lib.OutputFormats, lib.Output) {
+			logger.Error("Unsupported output format", zap.String("Output", lib.Output), zap.Strings("Supported", lib.OutputFormats))
+			os.Exit(lib.ExitValidation)
+		}
+		args := append([]string{firstNonEmpty(*alterFlag, *alterLongFlag)}, flag.Args()...)
+		executeCommand(ctx, logger, args...)
 	} else {
 		// If an unknown flag is provided, print custom message
 		fmt.Printf("Unknown flag provided: %s\n", os.Args[1:])
@@ -60,79 +149,421 @@ func NormalMode(logger zaplog.Logger) {
 	}
 }
 
-func executeCommand(logger zaplog.Logger, args ...string) {
-	if len(args) != 2 {
-		logger.Warn("Invalid number of arguments for alter resource. Expected 2, got ", zap.Int("LenArgs", len(args)))
+func executeCommand(ctx context.Context, logger zaplog.Logger, args ...string) {
+	lib.RunID = utils.NewRunID()
+	lib.Operator = utils.CurrentOperator()
+	startedAt := time.Now()
+
+	switch len(args) {
+	case 1:
+		lib.CSVPath = args[0]
+	case 2:
+		// Legacy form: -a <kubeconfig> <csv>
+		lib.Kubeconfig, lib.CSVPath = args[0], args[1]
+	default:
+		logger.Warn("Invalid number of arguments for alter resource. Expected 1 or 2, got ", zap.Int("LenArgs", len(args)))
 		flag.Usage()
-		return
+		exitRun([]lib.ResourceInfo{runResult(lib.AlterStatusInvalid, fmt.Errorf("expected 1 or 2 arguments, got %d", len(args)))}, startedAt, lib.ExitValidation, logger)
 	}
 
-	lib.Kubeconfig, lib.CSVPath = args[0], args[1]
-
-	config, err := clientcmd.BuildConfigFromFlags("", lib.Kubeconfig)
+	header, lines, lineNumbers, err := utils.ParseCSV(lib.CSVPath)
 	if err != nil {
-		logger.Error("Error building kubeconfig", zap.Error(err))
-		os.Exit(1)
+		logger.Error("Error parsing CSV file", zap.Error(err))
+		exitRun([]lib.ResourceInfo{runResult(lib.AlterStatusInvalid, err)}, startedAt, lib.ExitValidation, logger)
+	}
+
+	if err := utils.ValidateHeader(header); err != nil {
+		logger.Error("Invalid CSV header", zap.Error(err))
+		exitRun([]lib.ResourceInfo{runResult(lib.AlterStatusInvalid, err)}, startedAt, lib.ExitValidation, logger)
 	}
 
-	clientset, err := kubernetes.NewForConfig(config)
+	webhook, err := notify.NewWebhook(lib.NotifyURL, lib.NotifyFormat, lib.NotifyTimeout, lib.NotifyRetries)
 	if err != nil {
-		logger.Error("Error creating clientset", zap.Error(err))
-		os.Exit(1)
+		logger.Error("Invalid notification webhook", zap.Error(err))
+		exitRun([]lib.ResourceInfo{runResult(lib.AlterStatusInvalid, err)}, startedAt, lib.ExitValidation, logger)
 	}
 
-	lines, err := utils.ParseCSV(lib.CSVPath)
+	// Every applied row is recorded right away, so that an interrupted run can be resumed
+	checkpointPath := utils.CheckpointPath(lib.CSVPath)
+	checkpoint, err := utils.OpenCheckpoint(checkpointPath, lib.Resume, lines, lineNumbers)
 	if err != nil {
-		logger.Error("Error parsing CSV file", zap.Error(err))
-		os.Exit(1)
+		logger.Error("Error opening the checkpoint", zap.String("Path", checkpointPath), zap.Error(err))
+		exitRun([]lib.ResourceInfo{runResult(lib.AlterStatusFailed, err)}, startedAt, lib.ExitFailed, logger)
+	}
+	if lib.Resume {
+		logger.Info("Resuming from the checkpoint", zap.String("Path", checkpointPath), zap.Int("AppliedRows", checkpoint.ResumedRows()))
+	}
+
+	// Assign every row to the clusters it should be applied to, keeping the order in which
+	// the clusters first appear so that the results are grouped per cluster.
+	// Every row ends up in the results, rows that are not applied carry the reason.
+	var clusterNames []string
+	var results []lib.ResourceInfo
+	rowsByCluster := make(map[string][]lib.ChangeRow)
+	for i, line := range lines {
+		row, err := utils.ParseChangeRow(header, line)
+		row.Line = lineNumbers[i]
+		if err != nil {
+			logger.Error("Invalid CSV line", zap.Int("LineNumber", row.Line), zap.Strings("Line", line), zap.Error(err))
+			results = append(results, rowResult(row.Cluster, row, lib.AlterStatusInvalid, err))
+			continue
+		}
+
+		kubeContexts := rowContexts(row, logger)
+		if len(kubeContexts) == 0 {
+			results = append(results, rowResult(row.Cluster, row, lib.AlterStatusSkipped, fmt.Errorf("cluster %s is not in --contexts", row.Cluster)))
+		}
+		for _, kubeContext := range kubeContexts {
+			if _, ok := rowsByCluster[kubeContext]; !ok {
+				clusterNames = append(clusterNames, kubeContext)
+			}
+			rowsByCluster[kubeContext] = append(rowsByCluster[kubeContext], row)
+		}
+	}
+
+	// Each cluster gets its own clientset, all of them are created before any row is applied
+	clusters := make(map[string]*utils.KubeCluster)
+	for _, kubeContext := range clusterNames {
+		cluster, err := utils.NewKubeCluster(lib.Kubeconfig, kubeContext, lib.Namespace)
+		if err != nil {
+			logger.Error("Error connecting to cluster", zap.String("Context", kubeContext), zap.Error(err))
+			for _, row := range rowsByCluster[kubeContext] {
+				results = append(results, rowResult(kubeContext, row, lib.AlterStatusFailed, err))
+			}
+			continue
+		}
+		clusters[kubeContext] = cluster
+	}
+
+	// A missing permission fails the run before anything is changed, instead of in the middle of it
+	if lib.Preflight {
+		unreachable, ok := preflight(ctx, clusterNames, clusters, rowsByCluster, logger)
+		results = append(results, unreachable...)
+		if !ok {
+			closeCheckpoint(checkpoint, !lib.Resume, logger)
+			exitRun(results, startedAt, lib.ExitPermission, logger)
+		}
 	}
+	connected := len(clusters)
 
-	var updates []lib.ResourceInfo
+	if err := webhook.Start(ctx, len(lines)); err != nil {
+		logger.Warn("Error notifying the start of the run", zap.Error(err))
+	}
+	notifyFailed(ctx, webhook, results, logger)
 
-	// Launch goroutines to handle each line of the CSV file
-	for _, line := range lines {
-		if len(line) != 9 {
-			logger.Error("Invalid CSV format. Expected 9 fields per line.")
+	for _, kubeContext := range clusterNames {
+		cluster, ok := clusters[kubeContext]
+		if !ok {
+			continue
+		}
+		if ctx.Err() != nil {
+			for _, row := range rowsByCluster[kubeContext] {
+				results = append(results, rowResult(kubeContext, row, lib.AlterStatusSkipped, errInterrupted))
+			}
 			continue
 		}
 
-		if !utils.ValidateFields(line) {
-			logger.Error("Empty field found in CSV.")
+		notified := len(results)
+		results = append(results, applyRows(ctx, cluster, rowsByCluster[kubeContext], checkpoint, logger)...)
+		notifyFailed(ctx, webhook, results[notified:], logger)
+	}
+
+	// The checkpoint is only kept to resume an interrupted run
+	closeCheckpoint(checkpoint, ctx.Err() == nil, logger)
+
+	if lib.ReportPath != "" {
+		if err := report.WriteHTMLReport(lib.ReportPath, lib.CSVPath, results, zaplog.LogExcerpt()); err != nil {
+			logger.Error("Error writing the HTML report", zap.String("Path", lib.ReportPath), zap.Error(err))
+		} else {
+			logger.Info("HTML report written", zap.String("Path", lib.ReportPath))
+		}
+	}
+
+	// The rows that did not succeed can be fixed and re-run on their own
+	failedPath := utils.FailedCSVPath(lib.CSVPath)
+	if written, err := utils.WriteFailedCSV(failedPath, header, lines, lineNumbers, results); err != nil {
+		logger.Error("Error writing the failed rows", zap.String("Path", failedPath), zap.Error(err))
+	} else if written > 0 {
+		logger.Info("Failed rows written", zap.String("Path", failedPath), zap.Int("Rows", written))
+	}
+
+	if err := table.PrintUpdates(results, lib.Output); err != nil {
+		logger.Error("Error writing the result report", zap.Error(err))
+		exitRun(results, startedAt, lib.ExitFailed, logger)
+	}
+
+	exitCode := lib.ExitCode(results)
+	if ctx.Err() != nil {
+		logger.Warn("Run interrupted, re-run with --resume to apply the remaining rows", zap.String("Checkpoint", checkpointPath))
+		exitCode = lib.ExitInterrupted
+	} else if connected == 0 && len(clusterNames) > 0 {
+		exitCode = lib.ExitConnection
+	}
+
+	// The summary is sent even when the run was interrupted
+	if err := webhook.Finish(context.WithoutCancel(ctx), results, exitCode); err != nil {
+		logger.Warn("Error notifying the end of the run", zap.Error(err))
+	}
+	exitRun(results, startedAt, exitCode, logger)
+}
+
+// exitRun records every row of the run in the journal for cops history and cops show, then exits
+func exitRun(results []lib.ResourceInfo, startedAt time.Time, exitCode int, logger zaplog.Logger) {
+	if err := utils.AppendJournal(lib.JournalPath, journalRecords(results, startedAt, exitCode)); err != nil {
+		logger.Error("Error writing the change journal", zap.String("Path", lib.JournalPath), zap.Error(err))
+	} else {
+		logger.Info("Run recorded in the change journal", zap.String("RunID", lib.RunID), zap.String("Path", lib.JournalPath))
+	}
+	os.Exit(exitCode)
+}
+
+// preflight reviews and prints the permissions the rows need on every cluster, ok is false when one is missing
+func preflight(ctx context.Context, clusterNames []string, clusters map[string]*utils.KubeCluster, rowsByCluster map[string][]lib.ChangeRow, logger zaplog.Logger) ([]lib.ResourceInfo, bool) {
+	var results []lib.ResourceInfo
+	var permissions []lib.Permission
+	for _, kubeContext := range clusterNames {
+		cluster, ok := clusters[kubeContext]
+		if !ok || ctx.Err() != nil {
 			continue
 		}
 
-		// Parse fields from the CSV line
-		workload := line[0]
-		containersName := line[1]
-		worktype := line[2]
-		namespace := line[3]
-		replicasCSV, err := strconv.Atoi(line[4])
+		reviewed, err := utils.CheckClusterPermissions(ctx, cluster, rowsByCluster[kubeContext])
 		if err != nil {
-			logger.Error("Error converting replicas to integer", zap.Error(err))
+			// A cluster that cannot be reviewed is dropped and its rows fail
+			logger.Error("Error checking permissions", zap.String("Context", cluster.Name), zap.Error(err))
+			delete(clusters, kubeContext)
+			for _, row := range rowsByCluster[kubeContext] {
+				results = append(results, rowResult(cluster.Name, row, lib.AlterStatusFailed, err))
+			}
 			continue
 		}
-		limitsCPU := line[5]
-		limitsMemory := line[6]
-		requestsCPU := line[7]
-		requestsMemory := line[8]
+		permissions = append(permissions, reviewed...)
+	}
+
+	if len(permissions) > 0 {
+		table.PrintPermissionTable(permissions)
+	}
 
-		// Update the workload based on worktype
-		if !utils.IsMilliCPU(limitsCPU) || !utils.IsMilliCPU(requestsCPU) {
-			logger.Error("CPU limit/request should be in milli-units (suffix 'm').", zap.String("Workload", workload), zap.String("Namespace", namespace))
+	missing := utils.MissingPermissions(permissions)
+	for _, permission := range missing {
+		logger.Error("Missing permission, no row was applied", zap.String("Cluster", permission.Cluster), zap.String("Namespace", permission.Namespace), zap.String("Permission", permission.Check.Name()), zap.String("Reason", permission.Reason))
+	}
+	return results, len(missing) == 0
+}
+
+// closeCheckpoint closes the checkpoint and deletes it when remove is set
+func closeCheckpoint(checkpoint *utils.Checkpoint, remove bool, logger zaplog.Logger) {
+	if !remove {
+		_ = checkpoint.Close()
+		return
+	}
+	if err := checkpoint.Remove(); err != nil {
+		logger.Warn("Error removing the checkpoint", zap.Error(err))
+	}
+}
+
+// notifyFailed sends a notification for every failed or invalid row of the results
+func notifyFailed(ctx context.Context, webhook *notify.Webhook, results []lib.ResourceInfo, logger zaplog.Logger) {
+	for _, result := range results {
+		if result.AlterStatus != lib.AlterStatusFailed && result.AlterStatus != lib.AlterStatusInvalid {
 			continue
 		}
+		if err := webhook.Failed(ctx, result); err != nil {
+			logger.Warn("Error notifying a failed row", zap.Int("LineNumber", result.Line), zap.String("Workload", result.Workload), zap.Error(err))
+		}
+	}
+}
+
+// rowContexts returns the kube contexts a row applies to. The cluster column of the row wins,
+// otherwise the row fans out to every context of --contexts, or to the selected context.
+func rowContexts(row lib.ChangeRow, logger zaplog.Logger) []string {
+	if row.Cluster == "" {
+		if len(lib.KubeContexts) > 0 {
+			return lib.KubeContexts
+		}
+		return []string{lib.KubeContext}
+	}
 
-		if !utils.IsMegaMemory(limitsMemory) || !utils.IsMegaMemory(requestsMemory) {
-			logger.Error("Memory limit/request should be in Mebibytes (suffix 'Mi').", zap.String("Workload", workload), zap.String("Namespace", namespace))
+	if len(lib.KubeContexts) > 0 && !slices.Contains(lib.KubeContexts, row.Cluster) {
+		logger.Warn("Cluster of the row is not in --contexts, skipping", zap.String("Cluster", row.Cluster), zap.String("Workload", row.Workload), zap.String("Namespace", row.Namespace))
+		return nil
+	}
+	return []string{row.Cluster}
+}
+
+// applyRows applies the rows of the change file to a single cluster. Rows targeting the same
+// workload are merged, so that every workload is updated and rolled out only once.
+func applyRows(ctx context.Context, cluster *utils.KubeCluster, rows []lib.ChangeRow, checkpoint *utils.Checkpoint, logger zaplog.Logger) []lib.ResourceInfo {
+	var targets []lib.ChangeRow
+	var results []lib.ResourceInfo
+
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			results = append(results, rowResult(cluster.Name, row, lib.AlterStatusSkipped, errInterrupted))
 			continue
 		}
 
-		update, err := utils.UpdateWorkload(clientset, worktype, namespace, workload, containersName, limitsCPU, limitsMemory, requestsCPU, requestsMemory, replicasCSV, logger)
+		// Rows without a namespace fall back to the namespace of the selected context
+		if row.Namespace == "" {
+			row.Namespace = cluster.Namespace
+		}
+
+		// Namespace globs and label selectors turn into one row per matched workload
+		expanded, err := utils.ExpandChangeRow(ctx, cluster.Clientset, row)
+		if err != nil && ctx.Err() != nil {
+			results = append(results, rowResult(cluster.Name, row, lib.AlterStatusSkipped, errInterrupted))
+			continue
+		}
 		if err != nil {
-			logger.Error("Error updating workload", zap.String("Workload", workload), zap.String("Namespace", namespace), zap.Error(err))
+			logger.Error("Error expanding workload targets", zap.String("Cluster", cluster.Name), zap.String("Workload", row.Workload), zap.String("Namespace", row.Namespace), zap.Error(err))
+			results = append(results, rowResult(cluster.Name, row, lib.AlterStatusFailed, err))
+			continue
+		}
+		if len(expanded) == 0 {
+			logger.Warn("No workloads matched the row", zap.String("Cluster", cluster.Name), zap.String("Workload", row.Workload), zap.String("Namespace", row.Namespace))
+			results = append(results, rowResult(cluster.Name, row, lib.AlterStatusSkipped, fmt.Errorf("no workloads matched the row")))
 			continue
 		}
-		updates = append(updates, update)
+
+		// Workloads the row was applied to by an earlier run are not changed again
+		for _, target := range expanded {
+			if checkpoint.Completed(cluster.Name, target) {
+				results = append(results, rowResult(cluster.Name, target, lib.AlterStatusSkipped, errors.New("already applied by an earlier run")))
+				continue
+			}
+			targets = append(targets, target)
+		}
+	}
+
+	changes, errs := utils.GroupChangeRows(targets)
+	for _, err := range errs {
+		logger.Error("Conflicting rows for the same workload", zap.String("Cluster", cluster.Name), zap.Error(err))
+		var rowErr *utils.RowError
+		if errors.As(err, &rowErr) {
+			results = append(results, rowResult(cluster.Name, rowErr.Row, lib.AlterStatusInvalid, err))
+		}
+	}
+
+	for _, change := range changes {
+		if ctx.Err() != nil {
+			results = append(results, changeResults(cluster.Name, change, lib.AlterStatusSkipped, errInterrupted)...)
+			continue
+		}
+
+		// A workload that is being updated is finished even when the run is interrupted
+		updateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), workloadUpdateTimeout)
+		changeUpdates, err := utils.UpdateWorkload(updateCtx, cluster.Clientset, change, logger)
+		cancel()
+		if err != nil {
+			logger.Error("Error updating workload", zap.String("Cluster", cluster.Name), zap.String("Workload", change.Workload), zap.String("Namespace", change.Namespace), zap.Error(err))
+			// Changes the workload cannot take as written are input problems, not failed updates
+			status := lib.AlterStatusFailed
+			var invalidErr *AlterResource.InvalidChangeError
+			if errors.As(err, &invalidErr) {
+				status = lib.AlterStatusInvalid
+			}
+			results = append(results, changeResults(cluster.Name, change, status, err)...)
+			continue
+		}
+		applied := true
+		for _, update := range changeUpdates {
+			update.Cluster = cluster.Name
+			results = append(results, update)
+			applied = applied && (update.AlterStatus == lib.AlterStatusSuccess || update.AlterStatus == lib.AlterStatusUnchanged)
+		}
+		if applied {
+			if err := checkpoint.Complete(cluster.Name, change); err != nil {
+				logger.Error("Error recording the checkpoint", zap.String("Cluster", cluster.Name), zap.String("Workload", change.Workload), zap.String("Namespace", change.Namespace), zap.Error(err))
+			}
+		}
+	}
+
+	return results
+}
+
+// journalRecords turns the results of the run into journal records
+func journalRecords(results []lib.ResourceInfo, startedAt time.Time, exitCode int) []lib.JournalRecord {
+	finishedAt := time.Now()
+
+	records := make([]lib.JournalRecord, 0, len(results))
+	for _, result := range results {
+		records = append(records, lib.JournalRecord{
+			RunID:      lib.RunID,
+			Operator:   lib.Operator,
+			Context:    result.Cluster,
+			CSVPath:    lib.CSVPath,
+			StartedAt:  startedAt,
+			FinishedAt: finishedAt,
+			Outcome:    result.AlterStatus,
+			ExitCode:   exitCode,
+			Change:     result,
+		})
+	}
+	return records
+}
+
+// runResult records a run that stopped before any row was read, together with the reason
+func runResult(status string, err error) lib.ResourceInfo {
+	return lib.ResourceInfo{
+		DataTime:    time.Now(),
+		AlterStatus: status,
+		Reason:      err.Error(),
+	}
+}
+
+// rowResult records a row of the change file that was not applied, together with the reason
+func rowResult(cluster string, row lib.ChangeRow, status string, err error) lib.ResourceInfo {
+	return lib.ResourceInfo{
+		DataTime:      time.Now(),
+		Line:          row.Line,
+		Cluster:       cluster,
+		Workload:      row.Workload,
+		ContainerName: row.ContainerName,
+		ContainerKind: row.ContainerKind,
+		WorkType:      row.WorkType,
+		Namespace:     row.Namespace,
+		AlterStatus:   status,
+		Reason:        err.Error(),
+	}
+}
+
+// changeResults records every row merged into a workload change that could not be applied
+func changeResults(cluster string, change lib.WorkloadChange, status string, err error) []lib.ResourceInfo {
+	var results []lib.ResourceInfo
+	for _, container := range change.Containers {
+		results = append(results, lib.ResourceInfo{
+			DataTime:      time.Now(),
+			Line:          container.Line,
+			Cluster:       cluster,
+			Workload:      change.Workload,
+			ContainerName: container.ContainerName,
+			ContainerKind: container.ContainerKind,
+			WorkType:      change.WorkType,
+			Namespace:     change.Namespace,
+			AlterStatus:   status,
+			Reason:        err.Error(),
+		})
+	}
+	return results
+}
+
+// firstNonEmpty returns the first non-empty value, used to merge short and long flags.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
-	table.PrintUpdateTable(updates)
+	return items
 }
@@ -0,0 +1,165 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: webhook
+ * @Version: 1.0.0
+ * @Date: 2026/10/19 10:52
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Webhook posts the events of a run to a notification webhook. A nil Webhook sends nothing.
+type Webhook struct {
+	URL     string
+	Format  string
+	Retries int
+	client  *http.Client
+}
+
+// NewWebhook returns a webhook for the url, or nil when no url is configured
+func NewWebhook(url, format string, timeout time.Duration, retries int) (*Webhook, error) {
+	if url == "" {
+		return nil, nil
+	}
+	if !slices.Contains(lib.NotifyFormats, format) {
+		return nil, fmt.Errorf("unsupported notification format %s, expected one of %s", format, strings.Join(lib.NotifyFormats, ", "))
+	}
+	if retries < 0 {
+		retries = 0
+	}
+	return &Webhook{URL: url, Format: format, Retries: retries, client: &http.Client{Timeout: timeout}}, nil
+}
+
+// Start announces the start of a run
+func (w *Webhook) Start(ctx context.Context, rows int) error {
+	notification := newNotification(lib.NotifyEventStart)
+	notification.Rows = rows
+	notification.Text = fmt.Sprintf("cops run %s started by %s: %d rows from %s", lib.RunID, lib.Operator, rows, lib.CSVPath)
+	return w.Send(ctx, notification)
+}
+
+// Failed reports a row that failed or is invalid
+func (w *Webhook) Failed(ctx context.Context, row lib.ResourceInfo) error {
+	notification := newNotification(lib.NotifyEventFailed)
+	notification.Row = &row
+	target := row.Workload
+	if row.Cluster != "" {
+		target = row.Cluster + "/" + target
+	}
+	notification.Text = fmt.Sprintf("cops run %s: %s row %d %s in namespace %s: %s", lib.RunID, row.AlterStatus, row.Line, target, row.Namespace, row.Reason)
+	return w.Send(ctx, notification)
+}
+
+// Finish sends the summary of the run
+func (w *Webhook) Finish(ctx context.Context, results []lib.ResourceInfo, exitCode int) error {
+	notification := newNotification(lib.NotifyEventFinish)
+	notification.Summary = make(map[string]int)
+	for _, result := range results {
+		notification.Summary[result.AlterStatus]++
+	}
+	notification.ExitCode = &exitCode
+
+	counts := []string{fmt.Sprintf("Total: %d", len(results))}
+	for _, status := range lib.AlterStatuses {
+		if count := notification.Summary[status]; count > 0 {
+			counts = append(counts, fmt.Sprintf("%s: %d", status, count))
+		}
+	}
+	notification.Text = fmt.Sprintf("cops run %s finished with exit code %d, %s", lib.RunID, exitCode, strings.Join(counts, ", "))
+	return w.Send(ctx, notification)
+}
+
+func newNotification(event string) lib.Notification {
+	return lib.Notification{Event: event, RunID: lib.RunID, Operator: lib.Operator, CSVPath: lib.CSVPath, Time: time.Now()}
+}
+
+// Send posts the notification in the payload format of the webhook. Connection errors, 429 and
+// 5xx responses are retried with a growing backoff.
+func (w *Webhook) Send(ctx context.Context, notification lib.Notification) error {
+	if w == nil {
+		return nil
+	}
+
+	body, err := json.Marshal(payload(w.Format, notification))
+	if err != nil {
+		return fmt.Errorf("error encoding notification: %v", err)
+	}
+
+	backoff := 500 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.Retries {
+			return fmt.Errorf("error sending %s notification: %v", notification.Event, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("error sending %s notification: %v", notification.Event, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends one request and tells if a failure is worth retrying
+func (w *Webhook) post(ctx context.Context, body []byte) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "cops/"+lib.Version)
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+		return retry, fmt.Errorf("webhook responded %s", response.Status)
+	}
+	return false, nil
+}
+
+// payload wraps the notification in the message format of the chat service
+func payload(format string, notification lib.Notification) interface{} {
+	switch format {
+	case lib.NotifyFormatSlack:
+		return map[string]interface{}{"text": notification.Text}
+	case lib.NotifyFormatTeams:
+		return map[string]interface{}{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  notification.Text,
+			"text":     notification.Text,
+		}
+	case lib.NotifyFormatDingTalk:
+		return map[string]interface{}{"msgtype": "text", "text": map[string]string{"content": notification.Text}}
+	case lib.NotifyFormatFeishu:
+		return map[string]interface{}{"msg_type": "text", "content": map[string]string{"text": notification.Text}}
+	default:
+		return notification
+	}
+}
@@ -0,0 +1,216 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Einic/cops/lib"
+)
+
+// newTestServer answers every request with the next status of statuses, the last one repeats. It
+// returns the server and the number of requests it received.
+func newTestServer(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(atomic.AddInt32(&requests, 1)) - 1
+		if i >= len(statuses) {
+			i = len(statuses) - 1
+		}
+		w.WriteHeader(statuses[i])
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestSendPayloadFormats(t *testing.T) {
+	text := "cops run test finished"
+
+	tests := []struct {
+		format string
+		want   map[string]interface{}
+	}{
+		{
+			format: lib.NotifyFormatSlack,
+			want:   map[string]interface{}{"text": text},
+		},
+		{
+			format: lib.NotifyFormatTeams,
+			want: map[string]interface{}{
+				"@type":    "MessageCard",
+				"@context": "https://schema.org/extensions",
+				"summary":  text,
+				"text":     text,
+			},
+		},
+		{
+			format: lib.NotifyFormatDingTalk,
+			want:   map[string]interface{}{"msgtype": "text", "text": map[string]interface{}{"content": text}},
+		},
+		{
+			format: lib.NotifyFormatFeishu,
+			want:   map[string]interface{}{"msg_type": "text", "content": map[string]interface{}{"text": text}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var got map[string]interface{}
+			var contentType string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+				body, _ := io.ReadAll(r.Body)
+				if err := json.Unmarshal(body, &got); err != nil {
+					t.Errorf("error decoding payload: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			webhook, err := NewWebhook(server.URL, tt.format, time.Second, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := webhook.Send(context.Background(), lib.Notification{Event: lib.NotifyEventFinish, Text: text}); err != nil {
+				t.Fatal(err)
+			}
+			if contentType != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", contentType)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("payload = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendGenericPayload(t *testing.T) {
+	var got lib.Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("error decoding payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	webhook, err := NewWebhook(server.URL, lib.NotifyFormatGeneric, time.Second, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []lib.ResourceInfo{{AlterStatus: lib.AlterStatusSuccess}, {AlterStatus: lib.AlterStatusSuccess}, {AlterStatus: lib.AlterStatusFailed}}
+	if err := webhook.Finish(context.Background(), results, lib.ExitPartial); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Event != lib.NotifyEventFinish {
+		t.Errorf("event = %q, want %q", got.Event, lib.NotifyEventFinish)
+	}
+	if got.ExitCode == nil || *got.ExitCode != lib.ExitPartial {
+		t.Errorf("exitCode = %v, want %d", got.ExitCode, lib.ExitPartial)
+	}
+	want := map[string]int{lib.AlterStatusSuccess: 2, lib.AlterStatusFailed: 1}
+	if !reflect.DeepEqual(got.Summary, want) {
+		t.Errorf("summary = %v, want %v", got.Summary, want)
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		retries      int
+		wantErr      bool
+		wantRequests int32
+	}{
+		{
+			name:         "5xx is retried",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			retries:      2,
+			wantRequests: 2,
+		},
+		{
+			name:         "429 is retried",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			retries:      2,
+			wantRequests: 2,
+		},
+		{
+			name:         "retries run out",
+			statuses:     []int{http.StatusBadGateway},
+			retries:      1,
+			wantErr:      true,
+			wantRequests: 2,
+		},
+		{
+			name:         "4xx is not retried",
+			statuses:     []int{http.StatusBadRequest, http.StatusOK},
+			retries:      2,
+			wantErr:      true,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newTestServer(t, tt.statuses...)
+			webhook, err := NewWebhook(server.URL, lib.NotifyFormatSlack, time.Second, tt.retries)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = webhook.Send(context.Background(), lib.Notification{Event: lib.NotifyEventStart})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(requests); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestSendTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	webhook, err := NewWebhook(server.URL, lib.NotifyFormatSlack, 50*time.Millisecond, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := webhook.Send(context.Background(), lib.Notification{Event: lib.NotifyEventStart}); err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Send took %s, the timeout is not applied", elapsed)
+	}
+}
+
+func TestNewWebhook(t *testing.T) {
+	if webhook, err := NewWebhook("", lib.NotifyFormatSlack, time.Second, 0); webhook != nil || err != nil {
+		t.Errorf("no url = %v, %v, want a nil webhook", webhook, err)
+	}
+	if _, err := NewWebhook("http://example.invalid", "irc", time.Second, 0); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+
+	// A nil webhook sends nothing
+	var webhook *Webhook
+	if err := webhook.Send(context.Background(), lib.Notification{}); err != nil {
+		t.Errorf("nil webhook: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: html_report
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 18:55
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	"html/template"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"os"
+	"strings"
+	"time"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+// NamespaceTotal sums the replicas and the requests/limits times replicas of the changed workloads in a namespace
+type NamespaceTotal struct {
+	Cluster               string
+	Namespace             string
+	CurrentReplicas       int
+	AlterReplicas         int
+	CurrentRequestsCPU    resource.Quantity
+	AlterRequestsCPU      resource.Quantity
+	CurrentRequestsMemory resource.Quantity
+	AlterRequestsMemory   resource.Quantity
+	CurrentLimitsCPU      resource.Quantity
+	AlterLimitsCPU        resource.Quantity
+	CurrentLimitsMemory   resource.Quantity
+	AlterLimitsMemory     resource.Quantity
+}
+
+// htmlReport is the data rendered by the report template
+type htmlReport struct {
+	GeneratedAt time.Time
+	Version     string
+	CSVPath     string
+	Updates     []lib.ResourceInfo
+	Summary     []statusCount
+	Totals      []NamespaceTotal
+	FailedRows  []lib.ResourceInfo
+	Log         []string
+}
+
+// statusCount is the number of rows with an alter status
+type statusCount struct {
+	Status string
+	Count  int
+}
+
+// WriteHTMLReport writes a single offline HTML file with the updates, the per-namespace totals,
+// the rows that were not applied successfully and the log excerpt of the run.
+func WriteHTMLReport(path, csvPath string, updates []lib.ResourceInfo, log []string) error {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"change": formatChange,
+		"class":  func(status string) string { return strings.ReplaceAll(status, " ", "") },
+		"time":   func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+	}).Parse(reportTemplate)
+	if err != nil {
+		return fmt.Errorf("error parsing report template: %v", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating report file: %v", err)
+	}
+	defer file.Close()
+
+	var applied, failedRows []lib.ResourceInfo
+	counts := make(map[string]int)
+	for _, update := range updates {
+		counts[update.AlterStatus]++
+		if update.RunStatus != "" {
+			applied = append(applied, update)
+		}
+		if update.AlterStatus != lib.AlterStatusSuccess && update.AlterStatus != lib.AlterStatusUnchanged {
+			failedRows = append(failedRows, update)
+		}
+	}
+	summary := make([]statusCount, 0, len(lib.AlterStatuses))
+	for _, status := range lib.AlterStatuses {
+		summary = append(summary, statusCount{Status: status, Count: counts[status]})
+	}
+
+	data := htmlReport{
+		GeneratedAt: time.Now(),
+		Version:     lib.Version,
+		CSVPath:     csvPath,
+		Updates:     updates,
+		Summary:     summary,
+		Totals:      NamespaceTotals(applied),
+		FailedRows:  failedRows,
+		Log:         log,
+	}
+	if err := tmpl.Execute(file, data); err != nil {
+		return fmt.Errorf("error rendering report: %v", err)
+	}
+	return nil
+}
+
+// NamespaceTotals sums the applied updates per cluster and namespace. The replicas of a workload are counted once,
+// and regular init containers are left out because they do not run next to the app containers.
+func NamespaceTotals(updates []lib.ResourceInfo) []NamespaceTotal {
+	var totals []NamespaceTotal
+	index := make(map[string]int)
+	counted := make(map[string]bool)
+
+	for _, update := range updates {
+		key := update.Cluster + "/" + update.Namespace
+		i, found := index[key]
+		if !found {
+			index[key] = len(totals)
+			i = len(totals)
+			totals = append(totals, NamespaceTotal{Cluster: update.Cluster, Namespace: update.Namespace})
+		}
+		total := &totals[i]
+
+		workloadKey := key + "/" + update.WorkType + "/" + update.Workload
+		if !counted[workloadKey] {
+			counted[workloadKey] = true
+			total.CurrentReplicas += update.CurrentReplicas
+			total.AlterReplicas += update.AlterReplicas
+		}
+
+		if update.ContainerKind == lib.ContainerKindInit {
+			continue
+		}
+		addTimes(&total.CurrentRequestsCPU, update.CurrentRequestsCPU, update.CurrentReplicas)
+		addTimes(&total.AlterRequestsCPU, update.AlterRequestsCPU, update.AlterReplicas)
+		addTimes(&total.CurrentRequestsMemory, update.CurrentRequestsMemory, update.CurrentReplicas)
+		addTimes(&total.AlterRequestsMemory, update.AlterRequestsMemory, update.AlterReplicas)
+		addTimes(&total.CurrentLimitsCPU, update.CurrentLimitsCPU, update.CurrentReplicas)
+		addTimes(&total.AlterLimitsCPU, update.AlterLimitsCPU, update.AlterReplicas)
+		addTimes(&total.CurrentLimitsMemory, update.CurrentLimitsMemory, update.CurrentReplicas)
+		addTimes(&total.AlterLimitsMemory, update.AlterLimitsMemory, update.AlterReplicas)
+	}
+
+	return totals
+}
+
+// addTimes adds a quantity times the replicas to the total, unset values count as zero
+func addTimes(total *resource.Quantity, value string, replicas int) {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return
+	}
+	total.Add(*resource.NewMilliQuantity(quantity.MilliValue()*int64(replicas), quantity.Format))
+}
+
+// formatChange renders a before and after value for the report
+func formatChange(current, alter interface{}) string {
+	return fmt.Sprintf("%s -> %s", noneIfEmpty(current), noneIfEmpty(alter))
+}
+
+func noneIfEmpty(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return "(none)"
+		}
+		return v
+	case resource.Quantity:
+		if v.IsZero() {
+			return "0"
+		}
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,55 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: access_review
+ * @Version: 1.0.0
+ * @Date: 2026/10/19 11:42
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package AlterResource
+
+import (
+	"context"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CheckPermissions reviews each check in the namespace with a SelfSubjectAccessReview
+func CheckPermissions(ctx context.Context, clientset *kubernetes.Clientset, namespace string, checks []lib.PermissionCheck) ([]lib.Permission, error) {
+	permissions := make([]lib.Permission, 0, len(checks))
+	for _, check := range checks {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: namespace,
+					Verb:      check.Verb,
+					Group:     check.Group,
+					Resource:  check.Resource,
+				},
+			},
+		}
+
+		result, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error reviewing %s in namespace %s: %v", check.Name(), namespace, err)
+		}
+
+		reason := result.Status.Reason
+		if result.Status.EvaluationError != "" {
+			reason = result.Status.EvaluationError
+		}
+		permissions = append(permissions, lib.Permission{
+			Namespace: namespace,
+			Check:     check,
+			Allowed:   result.Status.Allowed,
+			Reason:    reason,
+		})
+	}
+	return permissions, nil
+}
@@ -13,137 +13,197 @@ package AlterResource
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/Einic/cops/lib"
 	"github.com/Einic/cops/zaplog"
 	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
-	"strings"
-	"sync"
+	"sort"
 )
 
-// UpdateLabels updates labels for the given workload
-func UpdateLabels(clientset *kubernetes.Clientset, workloadName, namespace string, logger zaplog.Logger) error {
-	if err := AddAppLabelToPods(clientset, workloadName, namespace, logger); err != nil {
-		return fmt.Errorf("error adding 'app' label to pods: %s", err.Error())
+// labelTarget is the pod template and selector of a workload checked for the label
+type labelTarget struct {
+	Name      string
+	WorkType  string
+	Namespace string
+	Labels    map[string]string
+	Selector  *metav1.LabelSelector
+}
+
+// AuditWorkloadLabels checks that the pod template of every Deployment/StatefulSet carries the label key
+// with the workload name as its value. Conforming workloads are only listed when includeConforming is set.
+func AuditWorkloadLabels(ctx context.Context, clientset *kubernetes.Clientset, namespace, labelKey string, includeConforming bool) ([]lib.LabelInfo, error) {
+	var targets []labelTarget
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing deployments: %v", err)
+	}
+	for _, deployment := range deployments.Items {
+		targets = append(targets, labelTarget{Name: deployment.Name, WorkType: "deployment", Namespace: deployment.Namespace, Labels: deployment.Spec.Template.Labels, Selector: deployment.Spec.Selector})
 	}
 
-	if err := UpdateAppLabelToWorkloadName(clientset, workloadName, namespace, logger); err != nil {
-		return fmt.Errorf("error updating 'app' label to pods: %s", err.Error())
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing statefulsets: %v", err)
+	}
+	for _, statefulSet := range statefulSets.Items {
+		targets = append(targets, labelTarget{Name: statefulSet.Name, WorkType: "statefulset", Namespace: statefulSet.Namespace, Labels: statefulSet.Spec.Template.Labels, Selector: statefulSet.Spec.Selector})
 	}
 
-	return nil
-}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].Namespace != targets[j].Namespace {
+			return targets[i].Namespace < targets[j].Namespace
+		}
+		return targets[i].Name < targets[j].Name
+	})
+
+	var labels []lib.LabelInfo
+	for _, target := range targets {
+		info := lib.LabelInfo{
+			Workload:      target.Name,
+			WorkType:      target.WorkType,
+			Namespace:     target.Namespace,
+			LabelKey:      labelKey,
+			ExpectedValue: target.Name,
+		}
 
-func GetRelatedPods(clientset *kubernetes.Clientset, workloadName, namespace string, logger zaplog.Logger) ([]corev1.Pod, error) {
-	//Try to get related Pods based on Deployment name
-	if _, err := clientset.AppsV1().Deployments(namespace).Get(context.TODO(), workloadName, metav1.GetOptions{}); err == nil {
-		//logger.Info("Related Pods found for the workload", zap.String("WorkloadName", workloadName), zap.String("Namespace", namespace))
-		return getPodsByOwnerReference(clientset, workloadName, namespace, logger)
-	}
+		value, found := target.Labels[labelKey]
+		info.CurrentValue = value
+		switch {
+		case value == target.Name:
+			info.LabelStatus = lib.LabelStatusConforming
+		case !found:
+			info.LabelStatus = lib.LabelStatusMissing
+		default:
+			info.LabelStatus = lib.LabelStatusMismatch
+		}
+
+		// The selector is immutable and must keep matching the template, so a key it uses cannot be changed
+		if info.LabelStatus != lib.LabelStatusConforming && selectorUsesKey(target.Selector, labelKey) {
+			info.Reason = fmt.Sprintf("label %q is part of the immutable workload selector", labelKey)
+		}
 
-	// Try to get related Pods based on StatefulSet name
-	if _, err := clientset.AppsV1().StatefulSets(namespace).Get(context.TODO(), workloadName, metav1.GetOptions{}); err == nil {
-		//logger.Info("Related Pods found for the workload", zap.String("WorkloadName", workloadName), zap.String("Namespace", namespace))
-		return getPodsByOwnerReference(clientset, workloadName, namespace, logger)
+		if info.LabelStatus == lib.LabelStatusConforming && !includeConforming {
+			continue
+		}
+		labels = append(labels, info)
 	}
 
-	// If neither is found, an empty list is returned.
-	logger.Warn("No related Pods found for the workload", zap.String("WorkloadName", workloadName), zap.String("Namespace", namespace))
-	return nil, nil
+	return labels, nil
 }
 
-func getPodsByOwnerReference(clientset *kubernetes.Clientset, workloadName, namespace string, logger zaplog.Logger) ([]corev1.Pod, error) {
-	pods := []corev1.Pod{}
-
-	// Get pods in the specified namespace
-	podList, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		logger.Error("Error listing pods in namespace", zap.String("Namespace", namespace), zap.Error(err))
-		return pods, err
+// selectorUsesKey reports whether the selector matches on the label key
+func selectorUsesKey(selector *metav1.LabelSelector, labelKey string) bool {
+	if selector == nil {
+		return false
+	}
+	if _, found := selector.MatchLabels[labelKey]; found {
+		return true
 	}
+	for _, expression := range selector.MatchExpressions {
+		if expression.Key == labelKey {
+			return true
+		}
+	}
+	return false
+}
 
-	// Use channels to collect matching Pods
-	podChan := make(chan corev1.Pod)
-	var wg sync.WaitGroup
+// FixWorkloadLabels patches the pod template of every non-conforming workload, which rolls out new pods
+// with the label. The running pods are never changed in place.
+func FixWorkloadLabels(ctx context.Context, clientset *kubernetes.Clientset, labels []lib.LabelInfo, logger zaplog.Logger) []lib.LabelInfo {
+	for i := range labels {
+		info := &labels[i]
+		if info.LabelStatus == lib.LabelStatusConforming {
+			continue
+		}
+		if info.Reason != "" {
+			info.LabelStatus = lib.LabelStatusSkipped
+			logger.Warn("Skipping pod template label", zap.String("WorkLoad", info.Workload), zap.String("Namespace", info.Namespace), zap.String("Reason", info.Reason))
+			continue
+		}
 
-	// Iterate through each pod to find those with matching OwnerReferences
-	for _, pod := range podList.Items {
-		wg.Add(1)
-		//fmt.Println("getPodsByOwnerReference range pod: ", pod)
-		go func(pod corev1.Pod) {
-			defer wg.Done()
-			for _, ownerRef := range pod.OwnerReferences {
-				// Check if the name of the OwnerReferences matches the workload name
-				if strings.HasPrefix(ownerRef.Name, workloadName+"-") {
-					podChan <- pod
-					break
-				}
+		patch, err := json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"labels": map[string]string{info.LabelKey: info.ExpectedValue},
+					},
+				},
+			},
+		})
+		if err == nil {
+			switch info.WorkType {
+			case "deployment":
+				_, err = clientset.AppsV1().Deployments(info.Namespace).Patch(ctx, info.Workload, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+			case "statefulset":
+				_, err = clientset.AppsV1().StatefulSets(info.Namespace).Patch(ctx, info.Workload, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+			default:
+				err = fmt.Errorf("unsupported worktype: %s", info.WorkType)
 			}
-		}(pod)
-	}
-	// Start a separate coroutine to close the channel and wait for all coroutines to complete
-	go func() {
-		wg.Wait()
-		close(podChan)
-	}()
-
-	// Read matching Pods from the channel
-	for pod := range podChan {
-		pods = append(pods, pod)
+		}
+		if err != nil {
+			info.LabelStatus = lib.LabelStatusFailed
+			info.Reason = err.Error()
+			logger.Error("Error patching pod template label", zap.String("WorkLoad", info.Workload), zap.String("Namespace", info.Namespace), zap.Error(err))
+			continue
+		}
+
+		info.LabelStatus = lib.LabelStatusFixed
+		logger.Info("Patched pod template label", zap.String("WorkLoad", info.Workload), zap.String("Namespace", info.Namespace), zap.String("Label", info.LabelKey+"="+info.ExpectedValue))
 	}
-	return pods, nil
+
+	return labels
 }
 
-func AddAppLabelToPods(clientset *kubernetes.Clientset, workloadName, namespace string, logger zaplog.Logger) error {
-	relatedPods, err := GetRelatedPods(clientset, workloadName, namespace, logger)
+// getDeploymentPods follows the ownership chain Pod -> ReplicaSet -> Deployment by UID
+func getDeploymentPods(ctx context.Context, clientset *kubernetes.Clientset, deployment *appsv1.Deployment) ([]corev1.Pod, error) {
+	labelSelector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("invalid selector of deployment %s: %v", deployment.Name, err)
 	}
 
-	for _, pod := range relatedPods {
-		if pod.Labels["app"] == "" {
-			podCopy := pod.DeepCopy()
-			if podCopy.Labels == nil {
-				podCopy.Labels = make(map[string]string)
-			}
-			podCopy.Labels["app"] = workloadName
+	replicaSetList, err := clientset.AppsV1().ReplicaSets(deployment.Namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("error listing replicasets in namespace %s: %v", deployment.Namespace, err)
+	}
 
-			_, err := clientset.CoreV1().Pods(namespace).Update(context.TODO(), podCopy, metav1.UpdateOptions{})
-			if err != nil {
-				logger.Error("Error adding 'app' label to pod", zap.String("PodName", pod.Name), zap.String("Namespace", namespace), zap.Error(err))
-				continue
-			}
-			logger.Info("Added 'app' label to pod", zap.String("PodName", pod.Name), zap.String("Namespace", namespace))
+	// Only the ReplicaSets controlled by this Deployment own its pods
+	owners := make(map[types.UID]bool)
+	for i := range replicaSetList.Items {
+		if controllerRef := metav1.GetControllerOfNoCopy(&replicaSetList.Items[i]); controllerRef != nil && controllerRef.UID == deployment.UID {
+			owners[replicaSetList.Items[i].UID] = true
 		}
 	}
 
-	return nil
+	return getPodsByOwnerReference(ctx, clientset, deployment.Namespace, deployment.Spec.Selector, owners)
 }
 
-func UpdateAppLabelToWorkloadName(clientset *kubernetes.Clientset, workloadName, namespace string, logger zaplog.Logger) error {
-	relatedPods, err := GetRelatedPods(clientset, workloadName, namespace, logger)
+// getPodsByOwnerReference lists the pods matching the workload selector whose controller is one of the owners
+func getPodsByOwnerReference(ctx context.Context, clientset *kubernetes.Clientset, namespace string, selector *metav1.LabelSelector, owners map[types.UID]bool) ([]corev1.Pod, error) {
+	pods := []corev1.Pod{}
+
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
 	if err != nil {
-		return err
+		return pods, fmt.Errorf("invalid workload selector: %v", err)
 	}
 
-	for _, pod := range relatedPods {
-		if pod.Labels["app"] != workloadName {
-			podCopy := pod.DeepCopy()
-			if podCopy.Labels == nil {
-				podCopy.Labels = make(map[string]string)
-			}
-			podCopy.Labels["app"] = workloadName
+	// Use the workload selector to narrow the pods in the namespace
+	podList, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector.String()})
+	if err != nil {
+		return pods, fmt.Errorf("error listing pods in namespace %s: %v", namespace, err)
+	}
 
-			_, err := clientset.CoreV1().Pods(namespace).Update(context.TODO(), podCopy, metav1.UpdateOptions{})
-			if err != nil {
-				logger.Error("Error updating 'app' label to match workloadName", zap.String("PodName", pod.Name), zap.String("Namespace", namespace), zap.Error(err))
-				continue
-			}
-			logger.Info("Updated 'app' label to match workloadName", zap.String("PodName", pod.Name), zap.String("Namespace", namespace))
+	// Pods sharing labels with another workload are told apart by the UID of their controller
+	for _, pod := range podList.Items {
+		if controllerRef := metav1.GetControllerOfNoCopy(&pod); controllerRef != nil && owners[controllerRef.UID] {
+			pods = append(pods, pod)
 		}
 	}
-
-	return nil
+	return pods, nil
 }
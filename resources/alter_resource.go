@@ -13,6 +13,7 @@ package AlterResource
 
 import (
 	"context"
+	"fmt"
 	"github.com/Einic/cops/lib"
 	"github.com/Einic/cops/zaplog"
 	"go.uber.org/zap"
@@ -20,79 +21,114 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"sort"
+	"strings"
 	"time"
 )
 
 // Function to update the deployment with new specifications
-func UpdateDeployment(clientset *kubernetes.Clientset, deployment *appsv1.Deployment, replicas int, containersName, limitsCPU, limitsMemory, requestsCPU, requestsMemory, namespace string, logger zaplog.Logger) lib.ResourceInfo {
+func UpdateDeployment(ctx context.Context, clientset *kubernetes.Clientset, deployment *appsv1.Deployment, change lib.WorkloadChange, logger zaplog.Logger) ([]lib.ResourceInfo, error) {
+	namespace := change.Namespace
 	currentReplicas := int(*deployment.Spec.Replicas)
-	alterReplicas := replicas
 
-	// Get the current container resources
-	CurrentLimitsCPU, CurrentLimitsMemory, CurrentRequestsCPU, CurrentRequestsMemory := GetCurrentContainerResources(deployment.Spec.Template.Spec.Containers, containersName)
+	// Resolve relative values against the current values
+	alterReplicas, err := ResolveReplicas(currentReplicas, change.Replicas)
+	if err != nil {
+		return nil, err
+	}
 
 	// Update the replicas
-	deployment.Spec.Replicas = Int32Ptr(int32(replicas))
+	deployment.Spec.Replicas = Int32Ptr(int32(alterReplicas))
 
 	// Update container resources
-	UpdateContainerResources(deployment.Spec.Template.Spec.Containers, containersName, limitsCPU, limitsMemory, requestsCPU, requestsMemory)
-
-	// Update the deployment
-	updatedDeployment, err := clientset.AppsV1().Deployments(deployment.Namespace).Update(context.TODO(), deployment, metav1.UpdateOptions{})
+	originalPodSpec := deployment.Spec.Template.Spec.DeepCopy()
+	updates, err := applyContainerChanges(&deployment.Spec.Template.Spec, change.Containers)
 	if err != nil {
-		logger.Error("Error updating deployment", zap.String("WorkLoad", deployment.Name), zap.String("Namespace", deployment.Namespace), zap.Error(err))
-		return lib.ResourceInfo{} // Return empty ResourceInfo in case of error
+		return nil, fmt.Errorf("deployment %s in namespace %s: %w", deployment.Name, namespace, err)
 	}
 
-	// Check if the deployment was actually updated
-	var alterStatus string
-	if err == nil && deploymentUpdated(updatedDeployment, deployment, replicas, containersName, limitsCPU, limitsMemory, requestsCPU, requestsMemory, namespace) {
-		// Deployment was actually updated
-		alterStatus = "Success"
-	} else {
-		// Deployment was not updated
-		alterStatus = "Failed"
+	// Derive and check the resources for the target QoS class
+	if err := applyTargetQoS(&deployment.Spec.Template.Spec, change.TargetQoS, updates); err != nil {
+		return nil, fmt.Errorf("deployment %s in namespace %s: %w", deployment.Name, namespace, err)
 	}
 
-	// Update labels
-	if err := UpdateLabels(clientset, deployment.Name, namespace, logger); err != nil {
-		logger.Error("Error updating labels for deployment", zap.String("WorkLoad", deployment.Name), zap.String("Namespace", deployment.Namespace), zap.Error(err))
+	// Rows that change nothing are not written, so that re-running a sizing file causes no rollout
+	unchanged := currentReplicas == alterReplicas && PodResourcesEqual(originalPodSpec, &deployment.Spec.Template.Spec)
+	updatedDeployment := deployment
+	if !unchanged {
+		// Warn early when the effective pod requests/limits would exceed a ResourceQuota
+		warnResourceQuota(ctx, clientset, namespace, deployment.Name, originalPodSpec, &deployment.Spec.Template.Spec, currentReplicas, alterReplicas, logger)
+
+		// Record the change and the previous resources on the deployment
+		if err := stampChangeAnnotations(&deployment.ObjectMeta, currentReplicas, originalPodSpec); err != nil {
+			return nil, fmt.Errorf("deployment %s in namespace %s: %v", deployment.Name, namespace, err)
+		}
+
+		// Update the deployment
+		updatedDeployment, err = clientset.AppsV1().Deployments(deployment.Namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error updating deployment %s in namespace %s: %v", deployment.Name, deployment.Namespace, err)
+		}
 	}
 
-	// Get Pod QoS
-	PodQos, err := GetPodQoS(clientset, deployment.Name, namespace, logger)
+	// Get Pod QoS, the running pods tell the current class and the new template the class after the change.
+	// The pods are found through their owner references, without running pods the current class is
+	// computed from the previous template.
+	pods, err := getDeploymentPods(ctx, clientset, deployment)
+	CurrentPodQos := ""
+	if err == nil {
+		CurrentPodQos, err = GetPodQoS(pods)
+	}
 	if err != nil {
-		logger.Warn("Failed to get Pod QoS for deployment", zap.String("WorkLoad", deployment.Name), zap.String("Namespace", deployment.Namespace), zap.Error(err))
-	}
-
-	// Create a ResourceInfo instance to pass to PrintResources function
-	resourceInfo := lib.ResourceInfo{
-		DataTime:              time.Now().Format("2006-01-02 15:04:05"),
-		Workload:              deployment.Name,
-		ContainerName:         containersName,
-		WorkType:              "deploy",
-		Namespace:             namespace,
-		CurrentReplicas:       currentReplicas,
-		AlterReplicas:         alterReplicas,
-		CurrentLimitsCPU:      CurrentLimitsCPU,
-		AlterLimitsCPU:        limitsCPU,
-		CurrentLimitsMemory:   CurrentLimitsMemory,
-		AlterLimitsMemory:     limitsMemory,
-		CurrentRequestsCPU:    CurrentRequestsCPU,
-		AlterRequestsCPU:      requestsCPU,
-		CurrentRequestsMemory: CurrentRequestsMemory,
-		AlterRequestsMemory:   requestsMemory,
-		PodQos:                PodQos,
-		RunStatus:             GetStatus(deployment.Status),
-		AlterStatus:           alterStatus,
-	}
-
-	return resourceInfo
+		logger.Info("Pod QoS for deployment computed from the pod template", zap.String("WorkLoad", deployment.Name), zap.String("Namespace", deployment.Namespace), zap.String("Reason", err.Error()))
+		CurrentPodQos = string(ComputePodQoS(originalPodSpec))
+	}
+	PodQos := string(ComputePodQoS(&deployment.Spec.Template.Spec))
+
+	// Fill in the workload details of every changed container
+	for i := range updates {
+		// Check if the deployment was actually updated
+		if unchanged {
+			updates[i].AlterStatus = lib.AlterStatusUnchanged
+		} else if deploymentUpdated(updatedDeployment, deployment, alterReplicas, updates[i]) {
+			updates[i].AlterStatus = lib.AlterStatusSuccess
+		} else {
+			updates[i].AlterStatus = lib.AlterStatusFailed
+			updates[i].Reason = "the deployment does not show the new values after the update"
+		}
+
+		updates[i].DataTime = time.Now()
+		updates[i].Workload = deployment.Name
+		updates[i].WorkType = "deploy"
+		updates[i].Namespace = namespace
+		updates[i].CurrentReplicas = currentReplicas
+		updates[i].AlterReplicas = alterReplicas
+		updates[i].CurrentPodQos = CurrentPodQos
+		updates[i].PodQos = PodQos
+		updates[i].RunStatus = GetStatus(deployment.Status)
+	}
+
+	// Put the change on the event timeline of the deployment, next to the rollout it causes
+	if !unchanged {
+		object := corev1.ObjectReference{
+			APIVersion:      "apps/v1",
+			Kind:            "Deployment",
+			Namespace:       updatedDeployment.Namespace,
+			Name:            updatedDeployment.Name,
+			UID:             updatedDeployment.UID,
+			ResourceVersion: updatedDeployment.ResourceVersion,
+		}
+		resized := !PodResourcesEqual(originalPodSpec, &deployment.Spec.Template.Spec)
+		recordChangeEvents(ctx, clientset, object, currentReplicas, alterReplicas, resized, updates, logger)
+	}
+
+	return updates, nil
 }
 
 // Check if the deployment was actually updated
-func deploymentUpdated(updatedDeployment, originalDeployment *appsv1.Deployment, replicas int, containersName, limitsCPU, limitsMemory, requestsCPU, requestsMemory, namespace string) bool {
+func deploymentUpdated(updatedDeployment, originalDeployment *appsv1.Deployment, replicas int, update lib.ResourceInfo) bool {
 	// Compare relevant fields to check if the deployment was actually updated
 	if updatedDeployment == nil || originalDeployment == nil {
 		return false
@@ -104,84 +140,111 @@ func deploymentUpdated(updatedDeployment, originalDeployment *appsv1.Deployment,
 	}
 
 	// Check if container resources are updated
-	updatedLimitsCPU, updatedLimitsMemory, updatedRequestsCPU, updatedRequestsMemory := GetCurrentContainerResources(updatedDeployment.Spec.Template.Spec.Containers, containersName)
-	if updatedLimitsCPU != limitsCPU || updatedLimitsMemory != limitsMemory || updatedRequestsCPU != requestsCPU || updatedRequestsMemory != requestsMemory {
-		return false
-	}
-
-	return true
+	return containerUpdated(&updatedDeployment.Spec.Template.Spec, update)
 }
 
 // Function to update the statefulset with new specifications
-func UpdateStatefulSet(clientset *kubernetes.Clientset, statefulSet *appsv1.StatefulSet, replicas int, containersName, limitsCPU, limitsMemory, requestsCPU, requestsMemory, namespace string, logger zaplog.Logger) lib.ResourceInfo {
+func UpdateStatefulSet(ctx context.Context, clientset *kubernetes.Clientset, statefulSet *appsv1.StatefulSet, change lib.WorkloadChange, logger zaplog.Logger) ([]lib.ResourceInfo, error) {
+	namespace := change.Namespace
 	currentReplicas := int(*statefulSet.Spec.Replicas)
-	alterReplicas := replicas
 
-	// Get the current container resources
-	CurrentLimitsCPU, CurrentLimitsMemory, CurrentRequestsCPU, CurrentRequestsMemory := GetCurrentContainerResources(statefulSet.Spec.Template.Spec.Containers, containersName)
+	// Resolve relative values against the current values
+	alterReplicas, err := ResolveReplicas(currentReplicas, change.Replicas)
+	if err != nil {
+		return nil, err
+	}
 
 	// Update the replicas
-	statefulSet.Spec.Replicas = Int32Ptr(int32(replicas))
+	statefulSet.Spec.Replicas = Int32Ptr(int32(alterReplicas))
 
 	// Update container resources
-	UpdateContainerResources(statefulSet.Spec.Template.Spec.Containers, containersName, limitsCPU, limitsMemory, requestsCPU, requestsMemory)
-
-	updatedStatefulSet, err := clientset.AppsV1().StatefulSets(statefulSet.Namespace).Update(context.TODO(), statefulSet, metav1.UpdateOptions{})
+	originalPodSpec := statefulSet.Spec.Template.Spec.DeepCopy()
+	updates, err := applyContainerChanges(&statefulSet.Spec.Template.Spec, change.Containers)
 	if err != nil {
-		logger.Error("Error updating statefulSet", zap.String("WorkLoad", statefulSet.Name), zap.String("Namespace", statefulSet.Namespace), zap.Error(err))
-
-		return lib.ResourceInfo{} // Return empty ResourceInfo in case of error
+		return nil, fmt.Errorf("statefulset %s in namespace %s: %w", statefulSet.Name, namespace, err)
 	}
 
-	// Check if the deployment was actually updated
-	var alterStatus string
-	if err == nil && StatefulSetUpdated(updatedStatefulSet, statefulSet, replicas, containersName, limitsCPU, limitsMemory, requestsCPU, requestsMemory, namespace) {
-		// StatefulSet was actually updated
-		alterStatus = "Success"
-	} else {
-		// StatefulSet was not updated
-		alterStatus = "Failed"
+	// Derive and check the resources for the target QoS class
+	if err := applyTargetQoS(&statefulSet.Spec.Template.Spec, change.TargetQoS, updates); err != nil {
+		return nil, fmt.Errorf("statefulset %s in namespace %s: %w", statefulSet.Name, namespace, err)
 	}
 
-	// Update labels
-	if err := UpdateLabels(clientset, statefulSet.Name, namespace, logger); err != nil {
-		logger.Error("Error updating labels for statefulset", zap.String("WorkLoad", statefulSet.Name), zap.String("Namespace", statefulSet.Namespace), zap.Error(err))
+	// Rows that change nothing are not written, so that re-running a sizing file causes no rollout
+	unchanged := currentReplicas == alterReplicas && PodResourcesEqual(originalPodSpec, &statefulSet.Spec.Template.Spec)
+	updatedStatefulSet := statefulSet
+	if !unchanged {
+		// Warn early when the effective pod requests/limits would exceed a ResourceQuota
+		warnResourceQuota(ctx, clientset, namespace, statefulSet.Name, originalPodSpec, &statefulSet.Spec.Template.Spec, currentReplicas, alterReplicas, logger)
+
+		// Record the change and the previous resources on the statefulset
+		if err := stampChangeAnnotations(&statefulSet.ObjectMeta, currentReplicas, originalPodSpec); err != nil {
+			return nil, fmt.Errorf("statefulset %s in namespace %s: %v", statefulSet.Name, namespace, err)
+		}
+
+		// Update the statefulset
+		updatedStatefulSet, err = clientset.AppsV1().StatefulSets(statefulSet.Namespace).Update(ctx, statefulSet, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error updating statefulset %s in namespace %s: %v", statefulSet.Name, statefulSet.Namespace, err)
+		}
 	}
 
-	// Get Pod QoS
-	PodQos, err := GetPodQoS(clientset, statefulSet.Name, namespace, logger)
+	// Get Pod QoS, the running pods tell the current class and the new template the class after the change.
+	// The pods are found through their owner references, without running pods the current class is
+	// computed from the previous template.
+	pods, err := getPodsByOwnerReference(ctx, clientset, namespace, statefulSet.Spec.Selector, map[types.UID]bool{statefulSet.UID: true})
+	CurrentPodQos := ""
+	if err == nil {
+		CurrentPodQos, err = GetPodQoS(pods)
+	}
 	if err != nil {
-		logger.Warn("Failed to get Pod QoS for statefulSet", zap.String("WorkLoad", statefulSet.Name), zap.String("Namespace", statefulSet.Namespace), zap.Error(err))
-	}
-
-	// Create a ResourceInfo instance to pass to PrintResources function
-	resourceInfo := lib.ResourceInfo{
-		DataTime:              time.Now().Format("2006-01-02 15:04:05"),
-		Workload:              statefulSet.Name,
-		ContainerName:         containersName,
-		WorkType:              "sts",
-		Namespace:             namespace,
-		CurrentReplicas:       currentReplicas,
-		AlterReplicas:         alterReplicas,
-		CurrentLimitsCPU:      CurrentLimitsCPU,
-		AlterLimitsCPU:        limitsCPU,
-		CurrentLimitsMemory:   CurrentLimitsMemory,
-		AlterLimitsMemory:     limitsMemory,
-		CurrentRequestsCPU:    CurrentRequestsCPU,
-		AlterRequestsCPU:      requestsCPU,
-		CurrentRequestsMemory: CurrentRequestsMemory,
-		AlterRequestsMemory:   requestsMemory,
-		PodQos:                PodQos,
-		RunStatus:             GetStatusStatefulSet(statefulSet.Status),
-		AlterStatus:           alterStatus,
-	}
-
-	return resourceInfo
+		logger.Info("Pod QoS for statefulSet computed from the pod template", zap.String("WorkLoad", statefulSet.Name), zap.String("Namespace", statefulSet.Namespace), zap.String("Reason", err.Error()))
+		CurrentPodQos = string(ComputePodQoS(originalPodSpec))
+	}
+	PodQos := string(ComputePodQoS(&statefulSet.Spec.Template.Spec))
+
+	// Fill in the workload details of every changed container
+	for i := range updates {
+		// Check if the statefulset was actually updated
+		if unchanged {
+			updates[i].AlterStatus = lib.AlterStatusUnchanged
+		} else if StatefulSetUpdated(updatedStatefulSet, statefulSet, alterReplicas, updates[i]) {
+			updates[i].AlterStatus = lib.AlterStatusSuccess
+		} else {
+			updates[i].AlterStatus = lib.AlterStatusFailed
+			updates[i].Reason = "the statefulset does not show the new values after the update"
+		}
+
+		updates[i].DataTime = time.Now()
+		updates[i].Workload = statefulSet.Name
+		updates[i].WorkType = "sts"
+		updates[i].Namespace = namespace
+		updates[i].CurrentReplicas = currentReplicas
+		updates[i].AlterReplicas = alterReplicas
+		updates[i].CurrentPodQos = CurrentPodQos
+		updates[i].PodQos = PodQos
+		updates[i].RunStatus = GetStatusStatefulSet(statefulSet.Status)
+	}
+
+	// Put the change on the event timeline of the statefulset, next to the rollout it causes
+	if !unchanged {
+		object := corev1.ObjectReference{
+			APIVersion:      "apps/v1",
+			Kind:            "StatefulSet",
+			Namespace:       updatedStatefulSet.Namespace,
+			Name:            updatedStatefulSet.Name,
+			UID:             updatedStatefulSet.UID,
+			ResourceVersion: updatedStatefulSet.ResourceVersion,
+		}
+		resized := !PodResourcesEqual(originalPodSpec, &statefulSet.Spec.Template.Spec)
+		recordChangeEvents(ctx, clientset, object, currentReplicas, alterReplicas, resized, updates, logger)
+	}
+
+	return updates, nil
 }
 
-// Check if the deployment was actually updated
-func StatefulSetUpdated(updatedStatefulSet, originalStatefulSet *appsv1.StatefulSet, replicas int, containersName, limitsCPU, limitsMemory, requestsCPU, requestsMemory, namespace string) bool {
-	// Compare relevant fields to check if the deployment was actually updated
+// Check if the statefulset was actually updated
+func StatefulSetUpdated(updatedStatefulSet, originalStatefulSet *appsv1.StatefulSet, replicas int, update lib.ResourceInfo) bool {
+	// Compare relevant fields to check if the statefulset was actually updated
 	if updatedStatefulSet == nil || originalStatefulSet == nil {
 		return false
 	}
@@ -192,31 +255,253 @@ func StatefulSetUpdated(updatedStatefulSet, originalStatefulSet *appsv1.Stateful
 	}
 
 	// Check if container resources are updated
-	updatedLimitsCPU, updatedLimitsMemory, updatedRequestsCPU, updatedRequestsMemory := GetCurrentContainerResources(updatedStatefulSet.Spec.Template.Spec.Containers, containersName)
-	if updatedLimitsCPU != limitsCPU || updatedLimitsMemory != limitsMemory || updatedRequestsCPU != requestsCPU || updatedRequestsMemory != requestsMemory {
+	return containerUpdated(&updatedStatefulSet.Spec.Template.Spec, update)
+}
+
+// containerUpdated checks if the container of an update carries the requested resources
+func containerUpdated(podSpec *corev1.PodSpec, update lib.ResourceInfo) bool {
+	updatedLimitsCPU, updatedLimitsMemory, updatedRequestsCPU, updatedRequestsMemory := GetCurrentContainerResources(ContainersOfKind(podSpec, update.ContainerKind), update.ContainerName)
+	if !quantityEqual(updatedLimitsCPU, update.AlterLimitsCPU) || !quantityEqual(updatedLimitsMemory, update.AlterLimitsMemory) ||
+		!quantityEqual(updatedRequestsCPU, update.AlterRequestsCPU) || !quantityEqual(updatedRequestsMemory, update.AlterRequestsMemory) {
 		return false
 	}
 
+	container := findContainer(ContainersOfKind(podSpec, update.ContainerKind), update.ContainerName)
+	for _, other := range update.OtherResources {
+		kind, name, _ := strings.Cut(other.Name, ".")
+		list := container.Resources.Requests
+		if kind == "limits" {
+			list = container.Resources.Limits
+		}
+		if !quantityEqual(quantityString(list, corev1.ResourceName(name)), other.Alter) {
+			return false
+		}
+	}
 	return true
 }
 
-// Function to update container resources
-func UpdateContainerResources(containers []corev1.Container, containersName, limitsCPU, limitsMemory, requestsCPU, requestsMemory string) {
-	for i := range containers {
-		if containers[i].Name == containersName {
-			containers[i].Resources.Limits = corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse(limitsCPU),
-				corev1.ResourceMemory: resource.MustParse(limitsMemory),
+// warnResourceQuota warns when the new pods of a workload would exceed a ResourceQuota of its namespace
+func warnResourceQuota(ctx context.Context, clientset *kubernetes.Clientset, namespace, workload string, originalPodSpec, podSpec *corev1.PodSpec, currentReplicas, alterReplicas int, logger zaplog.Logger) {
+	quotaList, err := clientset.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Warn("Error listing ResourceQuotas, the quota check is skipped", zap.String("WorkLoad", workload), zap.String("Namespace", namespace), zap.Error(err))
+		return
+	}
+	if err := CheckResourceQuota(quotaList.Items, originalPodSpec, podSpec, currentReplicas, alterReplicas); err != nil {
+		logger.Warn("ResourceQuota may block the new pods", zap.String("WorkLoad", workload), zap.String("Namespace", namespace), zap.Error(err))
+	}
+}
+
+// InvalidChangeError is the error of a change that cannot be applied to the workload as written
+type InvalidChangeError struct {
+	Err error
+}
+
+func (e *InvalidChangeError) Error() string {
+	return e.Err.Error()
+}
+
+// applyContainerChanges resolves and applies every container change of a workload. The container name
+// of a change may be a name, '*' or a glob. It returns one ResourceInfo per changed container.
+func applyContainerChanges(podSpec *corev1.PodSpec, changes []lib.ContainerChange) ([]lib.ResourceInfo, error) {
+	var updates []lib.ResourceInfo
+	changed := make(map[string]bool)
+
+	for _, change := range changes {
+		candidates := ContainersOfKind(podSpec, change.ContainerKind)
+		names, err := MatchContainerNames(candidates, change.ContainerName)
+		if err != nil {
+			return nil, &InvalidChangeError{Err: err}
+		}
+
+		// Init containers and sidecars live in InitContainers, app containers in Containers
+		containers := podSpec.Containers
+		if change.ContainerKind == lib.ContainerKindInit || change.ContainerKind == lib.ContainerKindSidecar {
+			containers = podSpec.InitContainers
+		}
+
+		for _, name := range names {
+			// Rows of the same workload must not overwrite each other
+			if changed[name] {
+				return nil, &InvalidChangeError{Err: fmt.Errorf("container %s is targeted by more than one row", name)}
+			}
+			changed[name] = true
+
+			// Get the current container resources
+			update := lib.ResourceInfo{Line: change.Line, ContainerName: name, ContainerKind: change.ContainerKind}
+			update.CurrentLimitsCPU, update.CurrentLimitsMemory, update.CurrentRequestsCPU, update.CurrentRequestsMemory = GetCurrentContainerResources(candidates, name)
+
+			// Relative values are resolved per container
+			update.AlterLimitsCPU, update.AlterLimitsMemory, update.AlterRequestsCPU, update.AlterRequestsMemory, err = resolveContainerResources(update.CurrentLimitsCPU, update.CurrentLimitsMemory, update.CurrentRequestsCPU, update.CurrentRequestsMemory, change.LimitsCPU, change.LimitsMemory, change.RequestsCPU, change.RequestsMemory)
+			if err != nil {
+				return nil, fmt.Errorf("container %s: %v", name, err)
+			}
+
+			// An empty value means the resource is removed from the container
+			limits, requests := corev1.ResourceList{}, corev1.ResourceList{}
+			var removeLimits, removeRequests []corev1.ResourceName
+			for _, target := range []struct {
+				list     corev1.ResourceList
+				remove   *[]corev1.ResourceName
+				kind     string
+				resource corev1.ResourceName
+				alter    string
+			}{
+				{limits, &removeLimits, "limits", corev1.ResourceCPU, update.AlterLimitsCPU},
+				{limits, &removeLimits, "limits", corev1.ResourceMemory, update.AlterLimitsMemory},
+				{requests, &removeRequests, "requests", corev1.ResourceCPU, update.AlterRequestsCPU},
+				{requests, &removeRequests, "requests", corev1.ResourceMemory, update.AlterRequestsMemory},
+			} {
+				if err := setOrRemove(target.list, target.remove, target.resource, target.alter); err != nil {
+					return nil, fmt.Errorf("container %s: %s: %v", name, target.kind, err)
+				}
+			}
+
+			// Other named resources such as ephemeral-storage
+			container := findContainer(candidates, name)
+			if update.OtherResources, err = resolveOtherResources(container, change, limits, requests, &removeLimits, &removeRequests); err != nil {
+				return nil, fmt.Errorf("container %s: %v", name, err)
 			}
-			containers[i].Resources.Requests = corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse(requestsCPU),
-				corev1.ResourceMemory: resource.MustParse(requestsMemory),
+
+			UpdateContainerResources(containers, name, limits, requests, removeLimits, removeRequests)
+			updates = append(updates, update)
+		}
+	}
+
+	return updates, nil
+}
+
+// applyTargetQoS derives and checks the resources for the target QoS class of a change, and refreshes
+// the after values of the updates with the derived limits and requests
+func applyTargetQoS(podSpec *corev1.PodSpec, targetQoS string, updates []lib.ResourceInfo) error {
+	if targetQoS == "" {
+		return nil
+	}
+
+	changedContainers := make([]string, 0, len(updates))
+	for _, update := range updates {
+		changedContainers = append(changedContainers, update.ContainerName)
+	}
+
+	if err := ApplyTargetQoS(podSpec, targetQoS, changedContainers); err != nil {
+		return &InvalidChangeError{Err: err}
+	}
+
+	for i := range updates {
+		updates[i].AlterLimitsCPU, updates[i].AlterLimitsMemory, updates[i].AlterRequestsCPU, updates[i].AlterRequestsMemory = GetCurrentContainerResources(ContainersOfKind(podSpec, updates[i].ContainerKind), updates[i].ContainerName)
+	}
+	return nil
+}
+
+// resolveOtherResources resolves the other named resources of a change against the container and adds
+// them to the limits and requests to set. It returns their before and after values.
+func resolveOtherResources(container corev1.Container, change lib.ContainerChange, limits, requests corev1.ResourceList, removeLimits, removeRequests *[]corev1.ResourceName) ([]lib.ResourceChange, error) {
+	var otherResources []lib.ResourceChange
+
+	for _, list := range []struct {
+		kind     string
+		values   map[string]string
+		current  corev1.ResourceList
+		resolved corev1.ResourceList
+		removed  *[]corev1.ResourceName
+	}{
+		{"limits", change.OtherLimits, container.Resources.Limits, limits, removeLimits},
+		{"requests", change.OtherRequests, container.Resources.Requests, requests, removeRequests},
+	} {
+		names := make([]string, 0, len(list.values))
+		for name := range list.values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			resourceName := corev1.ResourceName(name)
+			current := quantityString(list.current, resourceName)
+
+			alter, err := ResolveQuantity(resourceName, current, list.values[name])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", list.kind, err)
 			}
+			if alter == "" {
+				*list.removed = append(*list.removed, resourceName)
+				otherResources = append(otherResources, lib.ResourceChange{Name: list.kind + "." + name, Current: current})
+				continue
+			}
+			quantity, err := resource.ParseQuantity(alter)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %s %q: %v", list.kind, name, alter, err)
+			}
+
+			list.resolved[resourceName] = quantity
+			otherResources = append(otherResources, lib.ResourceChange{Name: list.kind + "." + name, Current: current, Alter: quantity.String()})
+		}
+	}
+
+	return otherResources, nil
+}
+
+// resolveContainerResources resolves relative limits and requests against the current values
+func resolveContainerResources(currentLimitsCPU, currentLimitsMemory, currentRequestsCPU, currentRequestsMemory, limitsCPU, limitsMemory, requestsCPU, requestsMemory string) (string, string, string, string, error) {
+	var err error
+	if limitsCPU, err = ResolveQuantity(corev1.ResourceCPU, currentLimitsCPU, limitsCPU); err != nil {
+		return "", "", "", "", fmt.Errorf("limits: %v", err)
+	}
+	if limitsMemory, err = ResolveQuantity(corev1.ResourceMemory, currentLimitsMemory, limitsMemory); err != nil {
+		return "", "", "", "", fmt.Errorf("limits: %v", err)
+	}
+	if requestsCPU, err = ResolveQuantity(corev1.ResourceCPU, currentRequestsCPU, requestsCPU); err != nil {
+		return "", "", "", "", fmt.Errorf("requests: %v", err)
+	}
+	if requestsMemory, err = ResolveQuantity(corev1.ResourceMemory, currentRequestsMemory, requestsMemory); err != nil {
+		return "", "", "", "", fmt.Errorf("requests: %v", err)
+	}
+	return limitsCPU, limitsMemory, requestsCPU, requestsMemory, nil
+}
+
+// Function to update container resources. The values are merged into the existing limits and requests,
+// so that resources which are not part of the change, such as hugepages or nvidia.com/gpu, are preserved.
+// Resources listed in removeLimits/removeRequests are deleted from the container.
+func UpdateContainerResources(containers []corev1.Container, containersName string, limits, requests corev1.ResourceList, removeLimits, removeRequests []corev1.ResourceName) {
+	for i := range containers {
+		if containers[i].Name == containersName {
+			containers[i].Resources.Limits = mergeResourceList(containers[i].Resources.Limits, limits, removeLimits)
+			containers[i].Resources.Requests = mergeResourceList(containers[i].Resources.Requests, requests, removeRequests)
 			break
 		}
 	}
 }
 
+// mergeResourceList sets and removes resources of a resource list, dropping the list once it is empty
+func mergeResourceList(list, set corev1.ResourceList, remove []corev1.ResourceName) corev1.ResourceList {
+	if list == nil {
+		list = corev1.ResourceList{}
+	}
+	for name, quantity := range set {
+		list[name] = quantity
+	}
+	for _, name := range remove {
+		delete(list, name)
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// setOrRemove adds a resolved value to the resources to set, or to the resources to remove when it is empty
+func setOrRemove(list corev1.ResourceList, remove *[]corev1.ResourceName, resourceName corev1.ResourceName, value string) error {
+	if value == "" {
+		*remove = append(*remove, resourceName)
+		return nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %v", resourceName, value, err)
+	}
+	list[resourceName] = quantity
+	return nil
+}
+
 // Helper function to convert int32 to int32 pointer
 func Int32Ptr(i int32) *int32 {
 	return &i
@@ -0,0 +1,77 @@
+package AlterResource
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Einic/cops/lib"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestApplyContainerChangesInvalid(t *testing.T) {
+	change := func(name, limitsCPU string) lib.ContainerChange {
+		return lib.ContainerChange{ContainerName: name, ContainerKind: lib.ContainerKindContainer, LimitsCPU: limitsCPU, LimitsMemory: "1Gi", RequestsCPU: "250m", RequestsMemory: "512Mi"}
+	}
+
+	tests := []struct {
+		name        string
+		changes     []lib.ContainerChange
+		targetQoS   string
+		wantErr     string
+		wantInvalid bool
+	}{
+		{
+			name:    "valid change",
+			changes: []lib.ContainerChange{change("app", "500m")},
+		},
+		{
+			name:        "container not found",
+			changes:     []lib.ContainerChange{change("web", "500m")},
+			wantErr:     "container not found",
+			wantInvalid: true,
+		},
+		{
+			name:        "container targeted by more than one row",
+			changes:     []lib.ContainerChange{change("app", "500m"), change("a*", "1")},
+			wantErr:     "targeted by more than one row",
+			wantInvalid: true,
+		},
+		{
+			name:        "unreachable target QoS",
+			changes:     []lib.ContainerChange{change("app", "500m")},
+			targetQoS:   string(corev1.PodQOSGuaranteed),
+			wantErr:     "target QoS Guaranteed cannot be reached",
+			wantInvalid: true,
+		},
+		{
+			name:    "relative value out of range is a failure",
+			changes: []lib.ContainerChange{change("app", "-200%")},
+			wantErr: "container app",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podSpec := corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "250m", "512Mi", "500m", "1Gi")}}
+			updates, err := applyContainerChanges(&podSpec, tt.changes)
+			if err == nil {
+				err = applyTargetQoS(&podSpec, tt.targetQoS, updates)
+			}
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			var invalidErr *InvalidChangeError
+			if got := errors.As(err, &invalidErr); got != tt.wantInvalid {
+				t.Errorf("invalid = %v, want %v", got, tt.wantInvalid)
+			}
+		})
+	}
+}
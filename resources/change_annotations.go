@@ -0,0 +1,74 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: change_annotations
+ * @Version: 1.0.0
+ * @Date: 2026/10/19 09:18
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package AlterResource
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+// stampChangeAnnotations records who changed the workload in which run, and the replicas and
+// container resources it had before, so that the change can be traced and rolled back from the cluster
+func stampChangeAnnotations(meta *metav1.ObjectMeta, replicas int, originalPodSpec *corev1.PodSpec) error {
+	previous, err := json.Marshal(SnapshotResources(replicas, originalPodSpec))
+	if err != nil {
+		return fmt.Errorf("error encoding previous resources: %v", err)
+	}
+
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[lib.AnnotationLastChangeID] = lib.RunID
+	meta.Annotations[lib.AnnotationChangedBy] = lib.Operator
+	meta.Annotations[lib.AnnotationChangedAt] = time.Now().UTC().Format(time.RFC3339)
+	meta.Annotations[lib.AnnotationPreviousResources] = string(previous)
+	return nil
+}
+
+// SnapshotResources returns the snapshot of the replicas and the limits and requests of every container
+func SnapshotResources(replicas int, podSpec *corev1.PodSpec) lib.PreviousResources {
+	snapshot := lib.PreviousResources{Replicas: replicas}
+	for _, container := range podSpec.InitContainers {
+		kind := lib.ContainerKindInit
+		if isSidecar(container) {
+			kind = lib.ContainerKindSidecar
+		}
+		snapshot.Containers = append(snapshot.Containers, containerResources(container, kind))
+	}
+	for _, container := range podSpec.Containers {
+		snapshot.Containers = append(snapshot.Containers, containerResources(container, lib.ContainerKindContainer))
+	}
+	return snapshot
+}
+
+// containerResources turns the limits and requests of a container into plain strings
+func containerResources(container corev1.Container, kind string) lib.ContainerResources {
+	resources := lib.ContainerResources{Name: container.Name, Kind: kind}
+	resources.Limits = resourceListStrings(container.Resources.Limits)
+	resources.Requests = resourceListStrings(container.Resources.Requests)
+	return resources
+}
+
+func resourceListStrings(list corev1.ResourceList) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(list))
+	for name, quantity := range list {
+		values[string(name)] = quantity.String()
+	}
+	return values
+}
@@ -0,0 +1,113 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: change_events
+ * @Version: 1.0.0
+ * @Date: 2026/10/19 10:02
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package AlterResource
+
+import (
+	"context"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	"github.com/Einic/cops/zaplog"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"strings"
+	"time"
+)
+
+// Reasons of the events cops creates on the workloads it changes
+const (
+	EventReasonResize = "CopsResize"
+	EventReasonScale  = "CopsScale"
+)
+
+// Events are rejected when the message is longer than this
+const maxEventMessageLength = 1024
+
+// recordChangeEvents creates a CopsScale event when the replicas changed and a CopsResize event when
+// the container resources changed. A failed event is only logged, the change itself is done.
+func recordChangeEvents(ctx context.Context, clientset *kubernetes.Clientset, object corev1.ObjectReference, currentReplicas, alterReplicas int, resized bool, updates []lib.ResourceInfo, logger zaplog.Logger) {
+	if currentReplicas != alterReplicas {
+		message := fmt.Sprintf("Scaled from %d to %d replicas by cops run %s", currentReplicas, alterReplicas, lib.RunID)
+		createChangeEvent(ctx, clientset, object, EventReasonScale, message, logger)
+	}
+
+	if resized {
+		var changes []string
+		for _, update := range updates {
+			if containerChanges := formatContainerChanges(update); containerChanges != "" {
+				changes = append(changes, containerChanges)
+			}
+		}
+		message := fmt.Sprintf("Resized by cops run %s: %s", lib.RunID, strings.Join(changes, "; "))
+		createChangeEvent(ctx, clientset, object, EventReasonResize, message, logger)
+	}
+}
+
+// createChangeEvent creates a Normal event on the workload
+func createChangeEvent(ctx context.Context, clientset *kubernetes.Clientset, object corev1.ObjectReference, reason, message string, logger zaplog.Logger) {
+	if len(message) > maxEventMessageLength {
+		message = message[:maxEventMessageLength-3] + "..."
+	}
+
+	now := metav1.NewTime(time.Now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", object.Name, now.UnixNano()),
+			Namespace: object.Namespace,
+		},
+		InvolvedObject:      object,
+		Reason:              reason,
+		Message:             message,
+		Source:              corev1.EventSource{Component: "cops"},
+		FirstTimestamp:      now,
+		LastTimestamp:       now,
+		Count:               1,
+		Type:                corev1.EventTypeNormal,
+		ReportingController: "cops",
+		ReportingInstance:   lib.Operator,
+	}
+
+	if _, err := clientset.CoreV1().Events(object.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		logger.Warn("Error creating event", zap.String("WorkLoad", object.Name), zap.String("Namespace", object.Namespace), zap.String("Reason", reason), zap.Error(err))
+	}
+}
+
+// formatContainerChanges renders the resources of a container that changed, e.g.
+// "container app: requests.cpu 100m -> 200m, limits.cpu 500m -> (none)"
+func formatContainerChanges(update lib.ResourceInfo) string {
+	values := []lib.ResourceChange{
+		{Name: "requests.cpu", Current: update.CurrentRequestsCPU, Alter: update.AlterRequestsCPU},
+		{Name: "requests.memory", Current: update.CurrentRequestsMemory, Alter: update.AlterRequestsMemory},
+		{Name: "limits.cpu", Current: update.CurrentLimitsCPU, Alter: update.AlterLimitsCPU},
+		{Name: "limits.memory", Current: update.CurrentLimitsMemory, Alter: update.AlterLimitsMemory},
+	}
+	values = append(values, update.OtherResources...)
+
+	var changes []string
+	for _, value := range values {
+		if !quantityEqual(value.Current, value.Alter) {
+			changes = append(changes, fmt.Sprintf("%s %s -> %s", value.Name, eventValue(value.Current), eventValue(value.Alter)))
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("container %s: %s", update.ContainerName, strings.Join(changes, ", "))
+}
+
+func eventValue(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
@@ -0,0 +1,153 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: drift_resource
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 14:32
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package AlterResource
+
+import (
+	"context"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	"github.com/Einic/cops/zaplog"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sort"
+)
+
+// workloadSizing is the part of a Deployment/StatefulSet that is compared across clusters
+type workloadSizing struct {
+	Name       string
+	WorkType   string
+	Namespace  string
+	Replicas   int
+	Containers []corev1.Container
+}
+
+// CompareWorkloads compares replicas and container resources of same-named workloads in two clusters.
+// Containers that are in sync are only returned when includeInSync is set.
+func CompareWorkloads(ctx context.Context, fromClientset, toClientset *kubernetes.Clientset, namespace string, includeInSync bool, logger zaplog.Logger) ([]lib.DriftInfo, error) {
+	fromWorkloads, err := listWorkloadSizing(ctx, fromClientset, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error listing source workloads: %v", err)
+	}
+
+	toWorkloads, err := listWorkloadSizing(ctx, toClientset, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error listing target workloads: %v", err)
+	}
+
+	keys := make([]string, 0, len(fromWorkloads))
+	for key := range fromWorkloads {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var drifts []lib.DriftInfo
+	for _, key := range keys {
+		from := fromWorkloads[key]
+		to, found := toWorkloads[key]
+		if !found {
+			logger.Warn("Workload is missing in the target cluster", zap.String("WorkLoad", from.Name), zap.String("Namespace", from.Namespace))
+		}
+
+		for _, container := range from.Containers {
+			drift := lib.DriftInfo{
+				Workload:      from.Name,
+				ContainerName: container.Name,
+				WorkType:      from.WorkType,
+				Namespace:     from.Namespace,
+				FromReplicas:  from.Replicas,
+				DriftStatus:   lib.DriftStatusMissing,
+			}
+			drift.FromLimitsCPU, drift.FromLimitsMemory, drift.FromRequestsCPU, drift.FromRequestsMemory = GetCurrentContainerResources(from.Containers, container.Name)
+
+			if found && hasContainer(to.Containers, container.Name) {
+				drift.ToReplicas = to.Replicas
+				drift.ToLimitsCPU, drift.ToLimitsMemory, drift.ToRequestsCPU, drift.ToRequestsMemory = GetCurrentContainerResources(to.Containers, container.Name)
+				drift.DriftStatus = driftStatus(drift)
+			}
+
+			if drift.DriftStatus == lib.DriftStatusInSync && !includeInSync {
+				continue
+			}
+			drifts = append(drifts, drift)
+		}
+	}
+
+	return drifts, nil
+}
+
+// listWorkloadSizing lists Deployments and StatefulSets keyed by worktype, namespace and name
+func listWorkloadSizing(ctx context.Context, clientset *kubernetes.Clientset, namespace string) (map[string]workloadSizing, error) {
+	workloads := make(map[string]workloadSizing)
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		sizing := workloadSizing{
+			Name:       deployment.Name,
+			WorkType:   "deployment",
+			Namespace:  deployment.Namespace,
+			Replicas:   replicasOrDefault(deployment.Spec.Replicas),
+			Containers: deployment.Spec.Template.Spec.Containers,
+		}
+		workloads[sizing.WorkType+"/"+sizing.Namespace+"/"+sizing.Name] = sizing
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		sizing := workloadSizing{
+			Name:       statefulSet.Name,
+			WorkType:   "statefulset",
+			Namespace:  statefulSet.Namespace,
+			Replicas:   replicasOrDefault(statefulSet.Spec.Replicas),
+			Containers: statefulSet.Spec.Template.Spec.Containers,
+		}
+		workloads[sizing.WorkType+"/"+sizing.Namespace+"/"+sizing.Name] = sizing
+	}
+
+	return workloads, nil
+}
+
+// driftStatus reports whether the source and target values of a container differ
+func driftStatus(drift lib.DriftInfo) string {
+	if drift.FromReplicas != drift.ToReplicas ||
+		!quantityEqual(drift.FromLimitsCPU, drift.ToLimitsCPU) ||
+		!quantityEqual(drift.FromLimitsMemory, drift.ToLimitsMemory) ||
+		!quantityEqual(drift.FromRequestsCPU, drift.ToRequestsCPU) ||
+		!quantityEqual(drift.FromRequestsMemory, drift.ToRequestsMemory) {
+		return lib.DriftStatusDrift
+	}
+	return lib.DriftStatusInSync
+}
+
+func hasContainer(containers []corev1.Container, containerName string) bool {
+	for _, container := range containers {
+		if container.Name == containerName {
+			return true
+		}
+	}
+	return false
+}
+
+// replicasOrDefault returns the replicas of a workload spec, which the API server defaults to 1
+func replicasOrDefault(replicas *int32) int {
+	if replicas == nil {
+		return 1
+	}
+	return int(*replicas)
+}
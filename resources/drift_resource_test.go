@@ -0,0 +1,30 @@
+package AlterResource
+
+import (
+	"testing"
+
+	"github.com/Einic/cops/lib"
+)
+
+func TestDriftStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		drift lib.DriftInfo
+		want  string
+	}{
+		{"same strings", lib.DriftInfo{FromReplicas: 2, ToReplicas: 2, FromLimitsCPU: "500m", ToLimitsCPU: "500m"}, lib.DriftStatusInSync},
+		{"cpu by value", lib.DriftInfo{FromLimitsCPU: "1", ToLimitsCPU: "1000m", FromRequestsCPU: "0.5", ToRequestsCPU: "500m"}, lib.DriftStatusInSync},
+		{"memory by value", lib.DriftInfo{FromLimitsMemory: "1Gi", ToLimitsMemory: "1024Mi", FromRequestsMemory: "512Mi", ToRequestsMemory: "536870912"}, lib.DriftStatusInSync},
+		{"replicas", lib.DriftInfo{FromReplicas: 2, ToReplicas: 3}, lib.DriftStatusDrift},
+		{"cpu value", lib.DriftInfo{FromLimitsCPU: "1", ToLimitsCPU: "900m"}, lib.DriftStatusDrift},
+		{"set and unset", lib.DriftInfo{FromRequestsMemory: "256Mi"}, lib.DriftStatusDrift},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := driftStatus(tt.drift); got != tt.want {
+				t.Errorf("driftStatus(%+v) = %s, want %s", tt.drift, got, tt.want)
+			}
+		})
+	}
+}
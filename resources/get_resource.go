@@ -15,78 +15,145 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/Einic/cops/zaplog"
-	"github.com/jedib0t/go-pretty/v6/text"
-	"go.uber.org/zap"
+	"github.com/Einic/cops/lib"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"path"
+	"strings"
 )
 
+// GetCurrentContainerResources returns the CPU/memory limits and requests of a container,
+// a resource that is not set is returned as an empty string.
 func GetCurrentContainerResources(containers []corev1.Container, containerName string) (string, string, string, string) {
 	for _, container := range containers {
 		if container.Name == containerName {
-			limitsCPU := container.Resources.Limits[corev1.ResourceCPU]
-			limitsMemory := container.Resources.Limits[corev1.ResourceMemory]
-			requestsCPU := container.Resources.Requests[corev1.ResourceCPU]
-			requestsMemory := container.Resources.Requests[corev1.ResourceMemory]
+			limitsCPU := quantityString(container.Resources.Limits, corev1.ResourceCPU)
+			limitsMemory := quantityString(container.Resources.Limits, corev1.ResourceMemory)
+			requestsCPU := quantityString(container.Resources.Requests, corev1.ResourceCPU)
+			requestsMemory := quantityString(container.Resources.Requests, corev1.ResourceMemory)
 
-			return limitsCPU.String(), limitsMemory.String(), requestsCPU.String(), requestsMemory.String()
+			return limitsCPU, limitsMemory, requestsCPU, requestsMemory
 		}
 	}
 
 	return "", "", "", ""
 }
 
-func GetStatusText(alterStatus string) string {
-	switch alterStatus {
-	case "Success":
-		return text.FgGreen.Sprint(alterStatus)
-	case "Failed":
-		return text.FgRed.Sprint(alterStatus)
-	default:
-		return alterStatus
+// findContainer returns the container with the given name, or an empty container if it does not exist
+func findContainer(containers []corev1.Container, containerName string) corev1.Container {
+	for _, container := range containers {
+		if container.Name == containerName {
+			return container
+		}
+	}
+	return corev1.Container{}
+}
+
+// quantityString returns a resource of a resource list as a string, or an empty string if it is not set
+func quantityString(list corev1.ResourceList, resourceName corev1.ResourceName) string {
+	if quantity, ok := list[resourceName]; ok {
+		return quantity.String()
+	}
+	return ""
+}
+
+// MatchContainerNames returns the names of the containers matching a container name, '*' or a glob such as 'php-*'.
+// It fails with the list of existing containers when nothing matches.
+func MatchContainerNames(containers []corev1.Container, pattern string) ([]string, error) {
+	var names, available []string
+	for _, container := range containers {
+		available = append(available, container.Name)
+		matched, err := path.Match(pattern, container.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid container pattern %q: %v", pattern, err)
+		}
+		if matched {
+			names = append(names, container.Name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("container not found: %q, available containers: %s", pattern, strings.Join(available, ", "))
 	}
+	return names, nil
 }
 
-// Function to get the status of the resource
+// GetStatus returns the plain run status of the deployment, the table adds the colors
 func GetStatus(status appsv1.DeploymentStatus) string {
 	if status.AvailableReplicas == status.Replicas {
-		return text.FgGreen.Sprint("Available")
+		return lib.RunStatusAvailable
 	} else if status.AvailableReplicas > 0 {
-		return text.FgYellow.Sprint("Partial Available")
+		return lib.RunStatusPartialAvailable
 	} else {
-		return text.FgRed.Sprint("Not Available")
+		return lib.RunStatusNotAvailable
 	}
 }
 
-// Function to get the status of the resource
+// GetStatusStatefulSet returns the plain run status of the statefulset, the table adds the colors
 func GetStatusStatefulSet(status appsv1.StatefulSetStatus) string {
 	if status.ReadyReplicas == status.Replicas {
-		return text.FgGreen.Sprint("Available")
+		return lib.RunStatusAvailable
 	} else if status.ReadyReplicas > 0 {
-		return text.FgYellow.Sprint("Partial Available")
+		return lib.RunStatusPartialAvailable
 	} else {
-		return text.FgRed.Sprint("Not Available")
+		return lib.RunStatusNotAvailable
 	}
 }
 
-// GetPodQoS retrieves the Quality of Service (QoS) of a pod.
-func GetPodQoS(clientset *kubernetes.Clientset, ResourcesName, namespace string, logger zaplog.Logger) (string, error) {
-	podList, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("app=%s", ResourcesName),
-	})
+// GetPodQoS retrieves the Quality of Service (QoS) class of the running pods of a workload.
+func GetPodQoS(pods []corev1.Pod) (string, error) {
+	// Assuming all pods have the same QoS, so we just pick the first one that has been admitted
+	for _, pod := range pods {
+		if pod.Status.QOSClass != "" {
+			return string(pod.Status.QOSClass), nil
+		}
+	}
+	return "", errors.New("no running pods found for the workload")
+}
+
+// ListNamespaceNames lists the names of all namespaces in the cluster
+func ListNamespaceNames(ctx context.Context, clientset *kubernetes.Clientset) ([]string, error) {
+	namespaceList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		logger.Error("Error fetching pods", zap.String("ResourcesName", ResourcesName), zap.String("Namespace", namespace), zap.Error(err))
-		return "", err
+		return nil, err
 	}
 
-	if len(podList.Items) == 0 {
-		errMsg := "It may be that the app label is missing, please handle it manually."
-		return "", errors.New(errMsg)
+	names := make([]string, 0, len(namespaceList.Items))
+	for _, namespace := range namespaceList.Items {
+		names = append(names, namespace.Name)
+	}
+	return names, nil
+}
+
+// ListWorkloadNames lists the names of the Deployments or StatefulSets in a namespace matching the label selector
+func ListWorkloadNames(ctx context.Context, clientset *kubernetes.Clientset, worktype, namespace, labelSelector string) ([]string, error) {
+	var names []string
+	listOptions := metav1.ListOptions{LabelSelector: labelSelector}
+
+	switch worktype {
+	case "deployment":
+		deploymentList, err := clientset.AppsV1().Deployments(namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, deployment := range deploymentList.Items {
+			names = append(names, deployment.Name)
+		}
+
+	case "statefulset":
+		statefulSetList, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, statefulSet := range statefulSetList.Items {
+			names = append(names, statefulSet.Name)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported worktype: %s", worktype)
 	}
 
-	// Assuming all pods have the same QoS, so we just pick the first one
-	return string(podList.Items[0].Status.QOSClass), nil
+	return names, nil
 }
@@ -0,0 +1,343 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: pod_resources
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 18:05
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package AlterResource
+
+import (
+	"fmt"
+	"github.com/Einic/cops/lib"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"slices"
+	"strings"
+)
+
+// ContainersOfKind returns the app containers, the init containers or the native sidecars
+// (init containers with restartPolicy Always) of a pod spec.
+func ContainersOfKind(podSpec *corev1.PodSpec, containerKind string) []corev1.Container {
+	switch containerKind {
+	case lib.ContainerKindInit, lib.ContainerKindSidecar:
+		var containers []corev1.Container
+		for _, container := range podSpec.InitContainers {
+			if isSidecar(container) == (containerKind == lib.ContainerKindSidecar) {
+				containers = append(containers, container)
+			}
+		}
+		return containers
+	default:
+		return podSpec.Containers
+	}
+}
+
+// isSidecar checks if an init container is a native sidecar that keeps running next to the app containers
+func isSidecar(container corev1.Container) bool {
+	return container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways
+}
+
+// EffectivePodRequests returns the requests the scheduler and ResourceQuota account for a pod.
+// App containers and sidecars are summed, while every regular init container only needs the
+// sidecars started before it, so the pod requests the higher of the two plus the pod overhead.
+func EffectivePodRequests(podSpec *corev1.PodSpec) corev1.ResourceList {
+	return effectivePodResources(podSpec, containerRequests)
+}
+
+// EffectivePodLimits returns the limits of a pod, following the same rules as EffectivePodRequests.
+func EffectivePodLimits(podSpec *corev1.PodSpec) corev1.ResourceList {
+	return effectivePodResources(podSpec, func(container corev1.Container) corev1.ResourceList {
+		return container.Resources.Limits
+	})
+}
+
+// containerRequests returns the requests of a container, where requests that are not set
+// default to the limits like the API server does
+func containerRequests(container corev1.Container) corev1.ResourceList {
+	requests := container.Resources.Requests.DeepCopy()
+	if requests == nil {
+		requests = corev1.ResourceList{}
+	}
+	for name, quantity := range container.Resources.Limits {
+		if _, ok := requests[name]; !ok {
+			requests[name] = quantity.DeepCopy()
+		}
+	}
+	return requests
+}
+
+func effectivePodResources(podSpec *corev1.PodSpec, resourcesOf func(corev1.Container) corev1.ResourceList) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for _, container := range podSpec.Containers {
+		addResourceList(total, resourcesOf(container))
+	}
+
+	sidecars := corev1.ResourceList{}
+	initPeak := corev1.ResourceList{}
+	for _, container := range podSpec.InitContainers {
+		current := corev1.ResourceList{}
+		if isSidecar(container) {
+			// Sidecars keep running, so they add to the app containers and to every later init container
+			addResourceList(total, resourcesOf(container))
+			addResourceList(sidecars, resourcesOf(container))
+			addResourceList(current, sidecars)
+		} else {
+			addResourceList(current, resourcesOf(container))
+			addResourceList(current, sidecars)
+		}
+		maxResourceList(initPeak, current)
+	}
+	maxResourceList(total, initPeak)
+
+	addResourceList(total, podSpec.Overhead)
+	return total
+}
+
+func addResourceList(list, add corev1.ResourceList) {
+	for name, quantity := range add {
+		if value, ok := list[name]; ok {
+			value.Add(quantity)
+			list[name] = value
+		} else {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+func maxResourceList(list, other corev1.ResourceList) {
+	for name, quantity := range other {
+		if value, ok := list[name]; !ok || quantity.Cmp(value) > 0 {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// CheckResourceQuota checks if replacing the pods of a workload would exceed the hard requests/limits
+// of the ResourceQuotas in its namespace, using the effective pod requests before and after the change.
+func CheckResourceQuota(quotas []corev1.ResourceQuota, originalPodSpec, podSpec *corev1.PodSpec, currentReplicas, alterReplicas int) error {
+	requests := [2]corev1.ResourceList{EffectivePodRequests(originalPodSpec), EffectivePodRequests(podSpec)}
+	limits := [2]corev1.ResourceList{EffectivePodLimits(originalPodSpec), EffectivePodLimits(podSpec)}
+
+	quotaResources := map[corev1.ResourceName]struct {
+		pod  [2]corev1.ResourceList
+		name corev1.ResourceName
+	}{
+		corev1.ResourceRequestsCPU:    {requests, corev1.ResourceCPU},
+		corev1.ResourceCPU:            {requests, corev1.ResourceCPU},
+		corev1.ResourceRequestsMemory: {requests, corev1.ResourceMemory},
+		corev1.ResourceMemory:         {requests, corev1.ResourceMemory},
+		corev1.ResourceLimitsCPU:      {limits, corev1.ResourceCPU},
+		corev1.ResourceLimitsMemory:   {limits, corev1.ResourceMemory},
+	}
+
+	var exceeded []string
+	for _, quota := range quotas {
+		for quotaName, hard := range quota.Status.Hard {
+			quotaResource, ok := quotaResources[quotaName]
+			if !ok {
+				continue
+			}
+
+			// projected = used - current pods + new pods
+			projected := quota.Status.Used[quotaName].DeepCopy()
+			projected.Sub(multiplyQuantity(quotaResource.pod[0][quotaResource.name], currentReplicas))
+			projected.Add(multiplyQuantity(quotaResource.pod[1][quotaResource.name], alterReplicas))
+
+			if projected.Cmp(hard) > 0 {
+				exceeded = append(exceeded, fmt.Sprintf("%s/%s: %s > %s", quota.Name, quotaName, projected.String(), hard.String()))
+			}
+		}
+	}
+
+	if len(exceeded) > 0 {
+		return fmt.Errorf("resource quota exceeded: %s", strings.Join(exceeded, ", "))
+	}
+	return nil
+}
+
+func multiplyQuantity(quantity resource.Quantity, times int) resource.Quantity {
+	return *resource.NewMilliQuantity(quantity.MilliValue()*int64(times), quantity.Format)
+}
+
+// ComputePodQoS computes the QoS class of a pod spec with the rules the kubelet uses. Init containers
+// count like app containers.
+func ComputePodQoS(podSpec *corev1.PodSpec) corev1.PodQOSClass {
+	requests := corev1.ResourceList{}
+	limits := corev1.ResourceList{}
+	isGuaranteed := true
+
+	allContainers := append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	for _, container := range allContainers {
+		for name, quantity := range containerRequests(container) {
+			if isQoSComputeResource(name) && quantity.Sign() > 0 {
+				addResourceList(requests, corev1.ResourceList{name: quantity})
+			}
+		}
+
+		limitsFound := 0
+		for name, quantity := range container.Resources.Limits {
+			if isQoSComputeResource(name) && quantity.Sign() > 0 {
+				addResourceList(limits, corev1.ResourceList{name: quantity})
+				limitsFound++
+			}
+		}
+		// Guaranteed needs both a CPU and a memory limit on every container
+		if limitsFound != 2 {
+			isGuaranteed = false
+		}
+	}
+
+	if len(requests) == 0 && len(limits) == 0 {
+		return corev1.PodQOSBestEffort
+	}
+
+	if isGuaranteed {
+		for name, request := range requests {
+			if limit, ok := limits[name]; !ok || limit.Cmp(request) != 0 {
+				isGuaranteed = false
+				break
+			}
+		}
+	}
+	if isGuaranteed && len(requests) == len(limits) {
+		return corev1.PodQOSGuaranteed
+	}
+	return corev1.PodQOSBurstable
+}
+
+// isQoSComputeResource checks if a resource takes part in the QoS class, only CPU and memory do
+func isQoSComputeResource(name corev1.ResourceName) bool {
+	return name == corev1.ResourceCPU || name == corev1.ResourceMemory
+}
+
+// ApplyTargetQoS makes a pod spec reach the target QoS class. For Guaranteed, the missing CPU/memory limit
+// or request of the changed containers is derived from the other one. It fails when the class cannot be
+// reached, naming the container that prevents it.
+func ApplyTargetQoS(podSpec *corev1.PodSpec, targetQoS string, changedContainers []string) error {
+	if targetQoS == "" {
+		return nil
+	}
+
+	if targetQoS == string(corev1.PodQOSGuaranteed) {
+		for i := range podSpec.InitContainers {
+			if slices.Contains(changedContainers, podSpec.InitContainers[i].Name) {
+				deriveGuaranteedResources(&podSpec.InitContainers[i])
+			}
+		}
+		for i := range podSpec.Containers {
+			if slices.Contains(changedContainers, podSpec.Containers[i].Name) {
+				deriveGuaranteedResources(&podSpec.Containers[i])
+			}
+		}
+	}
+
+	if qosClass := ComputePodQoS(podSpec); string(qosClass) != targetQoS {
+		return fmt.Errorf("target QoS %s cannot be reached, the pod would be %s: %s", targetQoS, qosClass, qosBlocker(podSpec, targetQoS))
+	}
+	return nil
+}
+
+// deriveGuaranteedResources sets the CPU/memory limit from the request or the request from the limit,
+// when only one of them is set
+func deriveGuaranteedResources(container *corev1.Container) {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		request, hasRequest := container.Resources.Requests[name]
+		limit, hasLimit := container.Resources.Limits[name]
+
+		switch {
+		case hasRequest && !hasLimit:
+			container.Resources.Limits = mergeResourceList(container.Resources.Limits, corev1.ResourceList{name: request.DeepCopy()}, nil)
+		case hasLimit && !hasRequest:
+			container.Resources.Requests = mergeResourceList(container.Resources.Requests, corev1.ResourceList{name: limit.DeepCopy()}, nil)
+		}
+	}
+}
+
+// qosBlocker explains why a pod spec does not have the target QoS class
+func qosBlocker(podSpec *corev1.PodSpec, targetQoS string) string {
+	allContainers := append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+
+	switch targetQoS {
+	case string(corev1.PodQOSGuaranteed):
+		for _, container := range allContainers {
+			requests := containerRequests(container)
+			for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				limit, ok := container.Resources.Limits[name]
+				if !ok || limit.Sign() <= 0 {
+					return fmt.Sprintf("container %s has no %s limit", container.Name, name)
+				}
+				if request := requests[name]; request.Cmp(limit) != 0 {
+					return fmt.Sprintf("container %s has %s request %s and limit %s", container.Name, name, request.String(), limit.String())
+				}
+			}
+		}
+	case string(corev1.PodQOSBestEffort):
+		for _, container := range allContainers {
+			for name := range containerRequests(container) {
+				if isQoSComputeResource(name) {
+					return fmt.Sprintf("container %s has a %s request or limit", container.Name, name)
+				}
+			}
+		}
+	case string(corev1.PodQOSBurstable):
+		if ComputePodQoS(podSpec) == corev1.PodQOSBestEffort {
+			return "no container has a CPU or memory request or limit"
+		}
+		return "every container has equal CPU and memory limits and requests"
+	}
+	return "unknown QoS class"
+}
+
+// PodResourcesEqual reports whether every container of the two pod specs has the same limits and requests
+func PodResourcesEqual(podSpec, other *corev1.PodSpec) bool {
+	containersEqual := func(containers, others []corev1.Container) bool {
+		if len(containers) != len(others) {
+			return false
+		}
+		for i := range containers {
+			if containers[i].Name != others[i].Name ||
+				!resourceListEqual(containers[i].Resources.Limits, others[i].Resources.Limits) ||
+				!resourceListEqual(containers[i].Resources.Requests, others[i].Resources.Requests) {
+				return false
+			}
+		}
+		return true
+	}
+	return containersEqual(podSpec.Containers, other.Containers) && containersEqual(podSpec.InitContainers, other.InitContainers)
+}
+
+// resourceListEqual compares two resource lists by quantity
+func resourceListEqual(list, other corev1.ResourceList) bool {
+	if len(list) != len(other) {
+		return false
+	}
+	for name, quantity := range list {
+		otherQuantity, found := other[name]
+		if !found || quantity.Cmp(otherQuantity) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// quantityEqual compares two quantity strings by value, so that 1 and 1000m or 1Gi and 1024Mi are equal.
+// An empty string is a resource that is not set.
+func quantityEqual(value, other string) bool {
+	if value == "" || other == "" {
+		return value == other
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return value == other
+	}
+	otherQuantity, err := resource.ParseQuantity(other)
+	if err != nil {
+		return value == other
+	}
+	return quantity.Cmp(otherQuantity) == 0
+}
@@ -0,0 +1,400 @@
+package AlterResource
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// testContainer returns a container with the requests and limits given as cpu and memory, an empty
+// value leaves the resource unset
+func testContainer(name, requestCPU, requestMemory, limitCPU, limitMemory string) corev1.Container {
+	return corev1.Container{
+		Name: name,
+		Resources: corev1.ResourceRequirements{
+			Requests: testResourceList(requestCPU, requestMemory),
+			Limits:   testResourceList(limitCPU, limitMemory),
+		},
+	}
+}
+
+func testSidecar(name, requestCPU, requestMemory, limitCPU, limitMemory string) corev1.Container {
+	container := testContainer(name, requestCPU, requestMemory, limitCPU, limitMemory)
+	restartPolicy := corev1.ContainerRestartPolicyAlways
+	container.RestartPolicy = &restartPolicy
+	return container
+}
+
+func testResourceList(cpu, memory string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	if cpu != "" {
+		list[corev1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		list[corev1.ResourceMemory] = resource.MustParse(memory)
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+func TestEffectivePodRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		podSpec    corev1.PodSpec
+		wantCPU    string
+		wantMemory string
+	}{
+		{
+			name: "app containers are summed",
+			podSpec: corev1.PodSpec{Containers: []corev1.Container{
+				testContainer("app", "100m", "128Mi", "", ""),
+				testContainer("proxy", "50m", "64Mi", "", ""),
+			}},
+			wantCPU:    "150m",
+			wantMemory: "192Mi",
+		},
+		{
+			name: "requests default to limits",
+			podSpec: corev1.PodSpec{Containers: []corev1.Container{
+				testContainer("app", "", "", "200m", "256Mi"),
+			}},
+			wantCPU:    "200m",
+			wantMemory: "256Mi",
+		},
+		{
+			name: "init container below the app containers",
+			podSpec: corev1.PodSpec{
+				InitContainers: []corev1.Container{testContainer("migrate", "100m", "64Mi", "", "")},
+				Containers:     []corev1.Container{testContainer("app", "200m", "128Mi", "", "")},
+			},
+			wantCPU:    "200m",
+			wantMemory: "128Mi",
+		},
+		{
+			name: "init container above the app containers",
+			podSpec: corev1.PodSpec{
+				InitContainers: []corev1.Container{testContainer("migrate", "1", "64Mi", "", "")},
+				Containers:     []corev1.Container{testContainer("app", "200m", "128Mi", "", "")},
+			},
+			wantCPU:    "1",
+			wantMemory: "128Mi",
+		},
+		{
+			name: "sidecar adds to the app containers",
+			podSpec: corev1.PodSpec{
+				InitContainers: []corev1.Container{testSidecar("proxy", "50m", "64Mi", "", "")},
+				Containers:     []corev1.Container{testContainer("app", "200m", "128Mi", "", "")},
+			},
+			wantCPU:    "250m",
+			wantMemory: "192Mi",
+		},
+		{
+			name: "init container after a sidecar needs the sidecar too",
+			podSpec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					testSidecar("proxy", "100m", "64Mi", "", ""),
+					testContainer("migrate", "500m", "64Mi", "", ""),
+				},
+				Containers: []corev1.Container{testContainer("app", "200m", "128Mi", "", "")},
+			},
+			wantCPU:    "600m",
+			wantMemory: "192Mi",
+		},
+		{
+			name: "init container before a sidecar does not need it",
+			podSpec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					testContainer("migrate", "500m", "64Mi", "", ""),
+					testSidecar("proxy", "100m", "64Mi", "", ""),
+				},
+				Containers: []corev1.Container{testContainer("app", "200m", "128Mi", "", "")},
+			},
+			wantCPU:    "500m",
+			wantMemory: "192Mi",
+		},
+		{
+			name: "pod overhead is added",
+			podSpec: corev1.PodSpec{
+				Containers: []corev1.Container{testContainer("app", "200m", "128Mi", "", "")},
+				Overhead:   testResourceList("250m", "120Mi"),
+			},
+			wantCPU:    "450m",
+			wantMemory: "248Mi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := EffectivePodRequests(&tt.podSpec)
+			for name, want := range map[corev1.ResourceName]string{corev1.ResourceCPU: tt.wantCPU, corev1.ResourceMemory: tt.wantMemory} {
+				got := requests[name]
+				if got.Cmp(resource.MustParse(want)) != 0 {
+					t.Errorf("%s = %s, want %s", name, got.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestComputePodQoS(t *testing.T) {
+	tests := []struct {
+		name    string
+		podSpec corev1.PodSpec
+		want    corev1.PodQOSClass
+	}{
+		{
+			name:    "no requests or limits",
+			podSpec: corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "", "", "", "")}},
+			want:    corev1.PodQOSBestEffort,
+		},
+		{
+			name:    "equal requests and limits",
+			podSpec: corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "500m", "256Mi", "500m", "256Mi")}},
+			want:    corev1.PodQOSGuaranteed,
+		},
+		{
+			name:    "limits only default the requests",
+			podSpec: corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "", "", "1", "1Gi")}},
+			want:    corev1.PodQOSGuaranteed,
+		},
+		{
+			name:    "equal by value in other units",
+			podSpec: corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "1000m", "1024Mi", "1", "1Gi")}},
+			want:    corev1.PodQOSGuaranteed,
+		},
+		{
+			name:    "request below the limit",
+			podSpec: corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "250m", "256Mi", "500m", "256Mi")}},
+			want:    corev1.PodQOSBurstable,
+		},
+		{
+			name:    "memory limit missing",
+			podSpec: corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "500m", "256Mi", "500m", "")}},
+			want:    corev1.PodQOSBurstable,
+		},
+		{
+			name: "one container without resources",
+			podSpec: corev1.PodSpec{Containers: []corev1.Container{
+				testContainer("app", "500m", "256Mi", "500m", "256Mi"),
+				testContainer("proxy", "", "", "", ""),
+			}},
+			want: corev1.PodQOSBurstable,
+		},
+		{
+			name: "init container counts like an app container",
+			podSpec: corev1.PodSpec{
+				InitContainers: []corev1.Container{testContainer("migrate", "100m", "", "", "")},
+				Containers:     []corev1.Container{testContainer("app", "500m", "256Mi", "500m", "256Mi")},
+			},
+			want: corev1.PodQOSBurstable,
+		},
+		{
+			name: "guaranteed sidecar",
+			podSpec: corev1.PodSpec{
+				InitContainers: []corev1.Container{testSidecar("proxy", "100m", "64Mi", "100m", "64Mi")},
+				Containers:     []corev1.Container{testContainer("app", "500m", "256Mi", "500m", "256Mi")},
+			},
+			want: corev1.PodQOSGuaranteed,
+		},
+		{
+			name: "init and app containers without resources",
+			podSpec: corev1.PodSpec{
+				InitContainers: []corev1.Container{testContainer("migrate", "", "", "", "")},
+				Containers:     []corev1.Container{testContainer("app", "", "", "", "")},
+			},
+			want: corev1.PodQOSBestEffort,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComputePodQoS(&tt.podSpec); got != tt.want {
+				t.Errorf("ComputePodQoS() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTargetQoS(t *testing.T) {
+	tests := []struct {
+		name      string
+		podSpec   corev1.PodSpec
+		targetQoS corev1.PodQOSClass
+		changed   []string
+		wantErr   string
+	}{
+		{
+			name:      "no target leaves the pod alone",
+			podSpec:   corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "250m", "", "", "")}},
+			targetQoS: "",
+			changed:   []string{"app"},
+		},
+		{
+			name:      "guaranteed from requests only",
+			podSpec:   corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "500m", "256Mi", "", "")}},
+			targetQoS: corev1.PodQOSGuaranteed,
+			changed:   []string{"app"},
+		},
+		{
+			name:      "guaranteed from limits only",
+			podSpec:   corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "", "", "1", "1Gi")}},
+			targetQoS: corev1.PodQOSGuaranteed,
+			changed:   []string{"app"},
+		},
+		{
+			name:      "guaranteed from a request and the other limit",
+			podSpec:   corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "500m", "", "", "256Mi")}},
+			targetQoS: corev1.PodQOSGuaranteed,
+			changed:   []string{"app"},
+		},
+		{
+			name:      "guaranteed with a request below the limit",
+			podSpec:   corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "250m", "256Mi", "500m", "256Mi")}},
+			targetQoS: corev1.PodQOSGuaranteed,
+			changed:   []string{"app"},
+			wantErr:   "container app has cpu request 250m and limit 500m",
+		},
+		{
+			name:      "unchanged containers are not derived",
+			podSpec:   corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "500m", "256Mi", "500m", "256Mi"), testContainer("proxy", "50m", "64Mi", "", "")}},
+			targetQoS: corev1.PodQOSGuaranteed,
+			changed:   []string{"app"},
+			wantErr:   "container proxy has no cpu limit",
+		},
+		{
+			name: "init container blocks guaranteed",
+			podSpec: corev1.PodSpec{
+				InitContainers: []corev1.Container{testContainer("migrate", "100m", "64Mi", "", "")},
+				Containers:     []corev1.Container{testContainer("app", "500m", "256Mi", "", "")},
+			},
+			targetQoS: corev1.PodQOSGuaranteed,
+			changed:   []string{"app"},
+			wantErr:   "container migrate has no cpu limit",
+		},
+		{
+			name: "sidecar blocks guaranteed",
+			podSpec: corev1.PodSpec{
+				InitContainers: []corev1.Container{testSidecar("proxy", "", "", "", "")},
+				Containers:     []corev1.Container{testContainer("app", "500m", "256Mi", "", "")},
+			},
+			targetQoS: corev1.PodQOSGuaranteed,
+			changed:   []string{"app"},
+			wantErr:   "container proxy has no cpu limit",
+		},
+		{
+			name: "changed sidecar is derived",
+			podSpec: corev1.PodSpec{
+				InitContainers: []corev1.Container{testSidecar("proxy", "50m", "64Mi", "", "")},
+				Containers:     []corev1.Container{testContainer("app", "500m", "256Mi", "", "")},
+			},
+			targetQoS: corev1.PodQOSGuaranteed,
+			changed:   []string{"app", "proxy"},
+		},
+		{
+			name:      "best effort with resources set",
+			podSpec:   corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "", "256Mi", "", "")}},
+			targetQoS: corev1.PodQOSBestEffort,
+			changed:   []string{"app"},
+			wantErr:   "container app has a memory request or limit",
+		},
+		{
+			name:      "best effort without resources",
+			podSpec:   corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "", "", "", "")}},
+			targetQoS: corev1.PodQOSBestEffort,
+			changed:   []string{"app"},
+		},
+		{
+			name:      "burstable from a guaranteed pod",
+			podSpec:   corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "500m", "256Mi", "500m", "256Mi")}},
+			targetQoS: corev1.PodQOSBurstable,
+			changed:   []string{"app"},
+			wantErr:   "every container has equal CPU and memory limits and requests",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := *tt.podSpec.DeepCopy()
+			err := ApplyTargetQoS(&tt.podSpec, string(tt.targetQoS), tt.changed)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ApplyTargetQoS() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyTargetQoS() error = %v", err)
+			}
+
+			want := tt.targetQoS
+			if want == "" {
+				want = ComputePodQoS(&before)
+			}
+			if got := ComputePodQoS(&tt.podSpec); got != want {
+				t.Errorf("QoS after ApplyTargetQoS() = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestCheckResourceQuota(t *testing.T) {
+	quota := func(hard, used string) corev1.ResourceQuota {
+		return corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse(hard)},
+				Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse(used)},
+			},
+		}
+	}
+	original := &corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "500m", "", "", "")}}
+	resized := &corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "1", "", "", "")}}
+
+	tests := []struct {
+		name          string
+		quotas        []corev1.ResourceQuota
+		alterReplicas int
+		wantErr       string
+	}{
+		{
+			name:          "no quota",
+			alterReplicas: 4,
+		},
+		{
+			name:          "within the quota",
+			quotas:        []corev1.ResourceQuota{quota("4", "1")},
+			alterReplicas: 2,
+		},
+		{
+			name:          "the current pods are replaced, not added",
+			quotas:        []corev1.ResourceQuota{quota("2", "1")},
+			alterReplicas: 2,
+		},
+		{
+			name:          "exceeded",
+			quotas:        []corev1.ResourceQuota{quota("2", "1")},
+			alterReplicas: 3,
+			wantErr:       "compute/requests.cpu: 3 > 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckResourceQuota(tt.quotas, original, resized, 2, tt.alterReplicas)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
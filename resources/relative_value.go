@@ -0,0 +1,201 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: relative_value
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 16:48
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package AlterResource
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const mebibyte = 1024 * 1024
+
+// decimalPattern matches a plain decimal number such as '20' or '1.5'
+var decimalPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// IsRelativeValue checks if a value is relative to the current value, e.g. '+20%', '-10%', 'x2',
+// or a signed amount in the given unit such as '+100m', '-256Mi' or '+1' when unit is empty.
+func IsRelativeValue(value, unit string) bool {
+	if len(value) < 2 {
+		return false
+	}
+
+	switch value[0] {
+	case 'x':
+		return decimalPattern.MatchString(value[1:])
+	case '+', '-':
+		amount := value[1:]
+		if strings.HasSuffix(amount, "%") {
+			return decimalPattern.MatchString(strings.TrimSuffix(amount, "%"))
+		}
+		amount = strings.TrimSuffix(amount, unit)
+		if amount == "" || (unit != "" && !strings.HasSuffix(value, unit)) {
+			return false
+		}
+		for _, r := range amount {
+			if !unicode.IsDigit(r) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRemoveValue checks if a value asks to remove the resource from the container, e.g. '-' or 'none'.
+func IsRemoveValue(value string) bool {
+	return value == "-" || strings.EqualFold(value, "none")
+}
+
+// ResolveQuantity resolves a relative value against the current quantity of a resource.
+// Absolute values are returned unchanged and removal values are returned as an empty string. CPU results are rounded up to milli-units and
+// memory results to Mebibytes, so that they keep the units used in the change file. A percentage or a factor
+// needs a current value, only a signed amount can be applied to a resource that is not set.
+func ResolveQuantity(resourceName corev1.ResourceName, current, value string) (string, error) {
+	if IsRemoveValue(value) {
+		return "", nil
+	}
+	if !isRelative(value) {
+		return value, nil
+	}
+	if current == "" && (value[0] == 'x' || strings.HasSuffix(value, "%")) {
+		return "", fmt.Errorf("cannot apply %q to %s, it is not set", value, resourceName)
+	}
+
+	currentQuantity := resource.Quantity{}
+	if current != "" {
+		parsed, err := resource.ParseQuantity(current)
+		if err != nil {
+			return "", fmt.Errorf("error parsing current %s %q: %v", resourceName, current, err)
+		}
+		currentQuantity = parsed
+	}
+
+	// Work in milli-units for CPU and in bytes for everything else
+	base := float64(currentQuantity.Value())
+	if resourceName == corev1.ResourceCPU {
+		base = float64(currentQuantity.MilliValue())
+	}
+
+	result, err := applyRelative(base, value, func(amount string) (float64, error) {
+		quantity, err := resource.ParseQuantity(amount)
+		if err != nil {
+			return 0, err
+		}
+		if resourceName == corev1.ResourceCPU {
+			return float64(quantity.MilliValue()), nil
+		}
+		return float64(quantity.Value()), nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("error resolving %s %q against %q: %v", resourceName, value, current, err)
+	}
+
+	switch resourceName {
+	case corev1.ResourceCPU:
+		return resource.NewMilliQuantity(int64(math.Ceil(result)), resource.DecimalSI).String(), nil
+	case corev1.ResourceMemory:
+		return resource.NewQuantity(int64(math.Ceil(result/mebibyte))*mebibyte, resource.BinarySI).String(), nil
+	case corev1.ResourceEphemeralStorage:
+		return resource.NewQuantity(int64(math.Ceil(result)), resource.BinarySI).String(), nil
+	default:
+		// Extended resources such as nvidia.com/gpu only accept whole numbers
+		return resource.NewQuantity(int64(math.Ceil(result)), resource.DecimalSI).String(), nil
+	}
+}
+
+// ResolveReplicas resolves a replicas value such as '3', '+1', '-50%' or 'x2' against the current replicas.
+func ResolveReplicas(current int, value string) (int, error) {
+	if !isRelative(value) {
+		replicas, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, err
+		}
+		if replicas > math.MaxInt32 {
+			return 0, fmt.Errorf("replicas %d exceed the maximum of %d", replicas, math.MaxInt32)
+		}
+		return replicas, nil
+	}
+
+	result, err := applyRelative(float64(current), value, parseDecimal)
+	if err != nil {
+		return 0, fmt.Errorf("error resolving replicas %q against %d: %v", value, current, err)
+	}
+	if result = math.Round(result); result > math.MaxInt32 {
+		return 0, fmt.Errorf("replicas %q against %d exceed the maximum of %d", value, current, math.MaxInt32)
+	}
+	return int(result), nil
+}
+
+// isRelative checks if a value starts with one of the relative operators
+func isRelative(value string) bool {
+	return len(value) > 1 && strings.ContainsRune("x+-", rune(value[0]))
+}
+
+// applyRelative applies a percentage, a factor or a signed amount to the base value
+func applyRelative(base float64, value string, parseAmount func(string) (float64, error)) (float64, error) {
+	var result float64
+	switch {
+	case value[0] == 'x':
+		factor, err := parseDecimal(value[1:])
+		if err != nil {
+			return 0, err
+		}
+		result = base * factor
+
+	case strings.HasSuffix(value, "%"):
+		percent, err := parseDecimal(strings.TrimSuffix(value[1:], "%"))
+		if err != nil {
+			return 0, err
+		}
+		if value[0] == '-' {
+			percent = -percent
+		}
+		result = base * (1 + percent/100)
+
+	case value[0] == '+' || value[0] == '-':
+		amount, err := parseAmount(value[1:])
+		if err != nil {
+			return 0, err
+		}
+		if value[0] == '-' {
+			amount = -amount
+		}
+		result = base + amount
+
+	default:
+		return 0, fmt.Errorf("invalid relative value")
+	}
+
+	if result < 0 {
+		return 0, fmt.Errorf("result is negative")
+	}
+	// Beyond int64 the conversion of the result would overflow
+	if math.IsInf(result, 0) || math.IsNaN(result) || result >= math.MaxInt64 {
+		return 0, fmt.Errorf("result is out of range")
+	}
+	return result, nil
+}
+
+// parseDecimal parses a plain decimal number, signs, exponents, Inf and NaN are rejected
+func parseDecimal(value string) (float64, error) {
+	if !decimalPattern.MatchString(value) {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return strconv.ParseFloat(value, 64)
+}
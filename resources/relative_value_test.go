@@ -0,0 +1,127 @@
+package AlterResource
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestResolveQuantity(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceName corev1.ResourceName
+		current      string
+		value        string
+		want         string
+		wantErr      bool
+	}{
+		{"absolute", corev1.ResourceCPU, "100m", "250m", "250m", false},
+		{"remove dash", corev1.ResourceCPU, "100m", "-", "", false},
+		{"remove none", corev1.ResourceMemory, "1Gi", "none", "", false},
+		{"cpu percent", corev1.ResourceCPU, "200m", "+20%", "240m", false},
+		{"cpu percent rounds up", corev1.ResourceCPU, "101m", "+10%", "112m", false},
+		{"cpu negative percent", corev1.ResourceCPU, "1", "-50%", "500m", false},
+		{"cpu factor", corev1.ResourceCPU, "250m", "x2", "500m", false},
+		{"cpu amount", corev1.ResourceCPU, "250m", "+100m", "350m", false},
+		{"cpu amount against unset", corev1.ResourceCPU, "", "+100m", "100m", false},
+		{"memory percent rounds to Mi", corev1.ResourceMemory, "1Gi", "+25%", "1280Mi", false},
+		{"memory amount", corev1.ResourceMemory, "1Gi", "-256Mi", "768Mi", false},
+		{"memory factor", corev1.ResourceMemory, "512Mi", "x2", "1Gi", false},
+		{"gpu amount", "nvidia.com/gpu", "1", "+1", "2", false},
+		{"negative result", corev1.ResourceMemory, "256Mi", "-512Mi", "", true},
+		{"percent against unset cpu", corev1.ResourceCPU, "", "+20%", "", true},
+		{"factor against unset cpu", corev1.ResourceCPU, "", "x2", "", true},
+		{"factor against unset memory", corev1.ResourceMemory, "", "x2", "", true},
+		{"invalid current", corev1.ResourceCPU, "abc", "+10%", "", true},
+		{"infinite factor", corev1.ResourceCPU, "100m", "xInf", "", true},
+		{"infinite percent", corev1.ResourceCPU, "100m", "+Inf%", "", true},
+		{"exponent factor", corev1.ResourceCPU, "100m", "x1e20", "", true},
+		{"nan factor", corev1.ResourceMemory, "1Gi", "xNaN", "", true},
+		{"factor out of range", corev1.ResourceCPU, "100m", "x100000000000000000000", "", true},
+		{"signed percent", corev1.ResourceCPU, "100m", "+-5%", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveQuantity(tt.resourceName, tt.current, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveQuantity(%s, %q, %q) error = %v, wantErr %v", tt.resourceName, tt.current, tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveQuantity(%s, %q, %q) = %q, want %q", tt.resourceName, tt.current, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveReplicas(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"absolute", 2, "5", 5, false},
+		{"plus one", 2, "+1", 3, false},
+		{"minus one", 2, "-1", 1, false},
+		{"factor", 3, "x2", 6, false},
+		{"percent rounds", 3, "-50%", 2, false},
+		{"negative result", 1, "-2", 0, true},
+		{"invalid", 1, "two", 0, true},
+		{"infinite factor", 2, "xInf", 0, true},
+		{"signed infinite factor", 2, "x+Inf", 0, true},
+		{"infinite percent", 2, "+Inf%", 0, true},
+		{"exponent factor", 2, "x1e20", 0, true},
+		{"signed percent", 2, "+-5%", 0, true},
+		{"above int32", 2, "x2000000000", 0, true},
+		{"absolute above int32", 2, "2147483648", 0, true},
+		{"int32 maximum", 1, "2147483647", 2147483647, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveReplicas(tt.current, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveReplicas(%d, %q) error = %v, wantErr %v", tt.current, tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveReplicas(%d, %q) = %d, want %d", tt.current, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRelativeValue(t *testing.T) {
+	tests := []struct {
+		value string
+		unit  string
+		want  bool
+	}{
+		{"+20%", "m", true},
+		{"-10%", "Mi", true},
+		{"x2", "m", true},
+		{"x1.5", "", true},
+		{"+100m", "m", true},
+		{"-256Mi", "Mi", true},
+		{"+1", "", true},
+		{"+100", "m", false},
+		{"100m", "m", false},
+		{"x", "", false},
+		{"+abcm", "m", false},
+		{"xInf", "", false},
+		{"x+Inf", "", false},
+		{"+Inf%", "", false},
+		{"-NaN%", "", false},
+		{"x1e20", "", false},
+		{"+1e2%", "", false},
+		{"+-5%", "", false},
+		{"x-2", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsRelativeValue(tt.value, tt.unit); got != tt.want {
+			t.Errorf("IsRelativeValue(%q, %q) = %v, want %v", tt.value, tt.unit, got, tt.want)
+		}
+	}
+}
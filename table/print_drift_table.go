@@ -0,0 +1,88 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: print_drift_table
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 15:02
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package table
+
+import (
+	"fmt"
+	"github.com/Einic/cops/lib"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+func PrintDriftTable(driftSlice []lib.DriftInfo, fromCluster, toCluster string) {
+	// Create a new table
+	rowConfigAutoMerge := table.RowConfig{AutoMerge: true}
+	t := newTableWriter()
+	t.SetTitle(fmt.Sprintf("%s -> %s", fromCluster, toCluster))
+
+	// Append the header row with bold formatting
+	headerRow := table.Row{"WORKLOAD", "CONTAINERNAME", "WORKTYPE", "NAMESPACE", "Replicas", "Requests (CPU)", "Requests (Memory)", "Limits (CPU)", "Limits (Memory)", "DRIFTSTATUS"}
+	t.AppendHeader(headerRow, rowConfigAutoMerge)
+
+	// Customize the table
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "WORKLOAD", AutoMerge: true},
+		{Name: "Replicas", Transformer: transformReplicas},
+		{Name: "Requests (CPU)", Transformer: transformColorfulValue},
+		{Name: "Requests (Memory)", Transformer: transformColorfulValue},
+		{Name: "Limits (CPU)", Transformer: transformColorfulValue},
+		{Name: "Limits (Memory)", Transformer: transformColorfulValue},
+	})
+
+	// Append rows for each container
+	for _, drift := range driftSlice {
+		t.AppendSeparator()
+		if drift.DriftStatus == lib.DriftStatusMissing {
+			t.AppendRow([]interface{}{
+				drift.Workload,
+				drift.ContainerName,
+				drift.WorkType,
+				drift.Namespace,
+				fmt.Sprintf("%d -> -", drift.FromReplicas),
+				fmt.Sprintf("%s -> -", noneIfEmpty(drift.FromRequestsCPU)),
+				fmt.Sprintf("%s -> -", noneIfEmpty(drift.FromRequestsMemory)),
+				fmt.Sprintf("%s -> -", noneIfEmpty(drift.FromLimitsCPU)),
+				fmt.Sprintf("%s -> -", noneIfEmpty(drift.FromLimitsMemory)),
+				getDriftStatusText(drift.DriftStatus),
+			})
+			continue
+		}
+		t.AppendRow([]interface{}{
+			drift.Workload,
+			drift.ContainerName,
+			drift.WorkType,
+			drift.Namespace,
+			fmt.Sprintf("%d -> %d", drift.FromReplicas, drift.ToReplicas),
+			formatChange(drift.FromRequestsCPU, drift.ToRequestsCPU),
+			formatChange(drift.FromRequestsMemory, drift.ToRequestsMemory),
+			formatChange(drift.FromLimitsCPU, drift.ToLimitsCPU),
+			formatChange(drift.FromLimitsMemory, drift.ToLimitsMemory),
+			getDriftStatusText(drift.DriftStatus),
+		})
+	}
+
+	// Render the table
+	t.Render()
+}
+
+func getDriftStatusText(driftStatus string) string {
+	switch driftStatus {
+	case lib.DriftStatusInSync:
+		return text.FgGreen.Sprint(driftStatus)
+	case lib.DriftStatusDrift:
+		return text.FgYellow.Sprint(driftStatus)
+	case lib.DriftStatusMissing:
+		return text.FgRed.Sprint(driftStatus)
+	default:
+		return driftStatus
+	}
+}
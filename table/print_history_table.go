@@ -0,0 +1,67 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: print_history_table
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 21:44
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package table
+
+import (
+	"fmt"
+	"github.com/Einic/cops/lib"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+func PrintHistoryTable(records []lib.JournalRecord) {
+	// Create a new table
+	rowConfigAutoMerge := table.RowConfig{AutoMerge: true}
+	t := newTableWriter()
+
+	// Append the header row with bold formatting
+	headerRow := table.Row{"RUNID", "DataTime", "OPERATOR", "CONTEXT", "NAMESPACE", "WORKLOAD", "CONTAINERNAME", "Replicas", "Requests (CPU)", "Requests (Memory)", "Limits (CPU)", "Limits (Memory)", "ALTERSTATUS"}
+	t.AppendHeader(headerRow, rowConfigAutoMerge)
+
+	// Customize the table
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "RUNID", AutoMerge: true},
+		{Name: "OPERATOR", AutoMerge: true},
+		{Name: "Replicas", Transformer: transformReplicas},
+		{Name: "Requests (CPU)", Transformer: transformColorfulValue},
+		{Name: "Requests (Memory)", Transformer: transformColorfulValue},
+		{Name: "Limits (CPU)", Transformer: transformColorfulValue},
+		{Name: "Limits (Memory)", Transformer: transformColorfulValue},
+		{Name: "ALTERSTATUS", Transformer: transformStatus},
+	})
+
+	// Append a row for each change, rows that were not applied show no values
+	for _, record := range records {
+		change := record.Change
+		row := table.Row{
+			record.RunID,
+			change.DataTime.Format("2006-01-02 15:04:05"),
+			record.Operator,
+			record.Context,
+			change.Namespace,
+			change.Workload,
+			change.ContainerName,
+			"-", "-", "-", "-", "-",
+			record.Outcome,
+		}
+		if change.RunStatus != "" {
+			row[7] = fmt.Sprintf("%d -> %d", change.CurrentReplicas, change.AlterReplicas)
+			row[8] = formatChange(change.CurrentRequestsCPU, change.AlterRequestsCPU)
+			row[9] = formatChange(change.CurrentRequestsMemory, change.AlterRequestsMemory)
+			row[10] = formatChange(change.CurrentLimitsCPU, change.AlterLimitsCPU)
+			row[11] = formatChange(change.CurrentLimitsMemory, change.AlterLimitsMemory)
+		}
+		t.AppendRow(row)
+	}
+
+	// Render the table
+	t.Render()
+}
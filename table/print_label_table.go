@@ -0,0 +1,62 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: print_label_table
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 17:20
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package table
+
+import (
+	"github.com/Einic/cops/lib"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+func PrintLabelTable(labelSlice []lib.LabelInfo) {
+	// Create a new table
+	rowConfigAutoMerge := table.RowConfig{AutoMerge: true}
+	t := newTableWriter()
+
+	// Append the header row with bold formatting
+	headerRow := table.Row{"NAMESPACE", "WORKLOAD", "WORKTYPE", "LABEL", "LABELVALUE", "LABELSTATUS", "REASON"}
+	t.AppendHeader(headerRow, rowConfigAutoMerge)
+
+	// Customize the table
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "NAMESPACE", AutoMerge: true},
+	})
+
+	// Append a row for each workload
+	for _, label := range labelSlice {
+		t.AppendRow([]interface{}{
+			label.Namespace,
+			label.Workload,
+			label.WorkType,
+			label.LabelKey,
+			formatChange(label.CurrentValue, label.ExpectedValue),
+			getLabelStatusText(label.LabelStatus),
+			label.Reason,
+		})
+	}
+
+	// Render the table
+	t.Render()
+}
+
+func getLabelStatusText(labelStatus string) string {
+	switch labelStatus {
+	case lib.LabelStatusConforming, lib.LabelStatusFixed:
+		return text.FgGreen.Sprint(labelStatus)
+	case lib.LabelStatusMissing, lib.LabelStatusMismatch, lib.LabelStatusSkipped:
+		return text.FgYellow.Sprint(labelStatus)
+	case lib.LabelStatusFailed:
+		return text.FgRed.Sprint(labelStatus)
+	default:
+		return labelStatus
+	}
+}
@@ -0,0 +1,121 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: print_output
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 18:02
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package table
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"os"
+	"sigs.k8s.io/yaml"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrintUpdates writes the updates to stdout in the given output format
+func PrintUpdates(updateSlice []lib.ResourceInfo, output string) error {
+	// Machine-readable outputs list an empty run as an empty list
+	if updateSlice == nil {
+		updateSlice = []lib.ResourceInfo{}
+	}
+
+	switch output {
+	case lib.OutputTable, "":
+		PrintUpdateTable(updateSlice)
+	case lib.OutputJSON:
+		data, err := json.MarshalIndent(updateSlice, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding json output: %v", err)
+		}
+		fmt.Println(string(data))
+	case lib.OutputYAML:
+		data, err := yaml.Marshal(updateSlice)
+		if err != nil {
+			return fmt.Errorf("error encoding yaml output: %v", err)
+		}
+		fmt.Print(string(data))
+	case lib.OutputCSV:
+		return printUpdateCSV(updateSlice)
+	case lib.OutputMarkdown:
+		printUpdateMarkdown(updateSlice)
+	default:
+		return fmt.Errorf("unsupported output format %q, expected one of %s", output, strings.Join(lib.OutputFormats, ", "))
+	}
+	return nil
+}
+
+// printUpdateCSV writes one record per update with a column for every before and after value
+func printUpdateCSV(updateSlice []lib.ResourceInfo) error {
+	writer := csv.NewWriter(os.Stdout)
+	_ = writer.Write([]string{
+		"time", "line", "cluster", "workload", "container_name", "container_kind", "worktype", "namespace",
+		"current_replicas", "alter_replicas",
+		"current_requests_cpu", "alter_requests_cpu", "current_requests_memory", "alter_requests_memory",
+		"current_limits_cpu", "alter_limits_cpu", "current_limits_memory", "alter_limits_memory",
+		"other_resources", "current_pod_qos", "pod_qos", "run_status", "alter_status", "reason",
+	})
+
+	for _, update := range updateSlice {
+		otherResources := make([]string, 0, len(update.OtherResources))
+		for _, resource := range update.OtherResources {
+			otherResources = append(otherResources, fmt.Sprintf("%s=%s->%s", resource.Name, resource.Current, resource.Alter))
+		}
+
+		_ = writer.Write([]string{
+			update.DataTime.Format(time.RFC3339),
+			strconv.Itoa(update.Line),
+			update.Cluster,
+			update.Workload,
+			update.ContainerName,
+			update.ContainerKind,
+			update.WorkType,
+			update.Namespace,
+			strconv.Itoa(update.CurrentReplicas),
+			strconv.Itoa(update.AlterReplicas),
+			update.CurrentRequestsCPU,
+			update.AlterRequestsCPU,
+			update.CurrentRequestsMemory,
+			update.AlterRequestsMemory,
+			update.CurrentLimitsCPU,
+			update.AlterLimitsCPU,
+			update.CurrentLimitsMemory,
+			update.AlterLimitsMemory,
+			strings.Join(otherResources, ";"),
+			update.CurrentPodQos,
+			update.PodQos,
+			update.RunStatus,
+			update.AlterStatus,
+			update.Reason,
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing csv output: %v", err)
+	}
+	return nil
+}
+
+// printUpdateMarkdown renders the update table without colors, ready to paste into a change ticket
+func printUpdateMarkdown(updateSlice []lib.ResourceInfo) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(updateHeader)
+	for _, update := range updateSlice {
+		t.AppendRow(updateRow(update))
+	}
+	t.SetCaption(summaryCaption(updateSlice))
+	t.RenderMarkdown()
+}
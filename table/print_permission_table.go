@@ -0,0 +1,94 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: print_permission_table
+ * @Version: 1.0.0
+ * @Date: 2026/10/19 12:10
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package table
+
+import (
+	"github.com/Einic/cops/lib"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"os"
+)
+
+// PrintPermissionTable prints the RBAC pre-flight to stderr as a matrix of namespaces and actions
+func PrintPermissionTable(permissions []lib.Permission) {
+	// Create a new table
+	rowConfigAutoMerge := table.RowConfig{AutoMerge: true}
+	t := newTableWriter()
+	t.SetOutputMirror(os.Stderr)
+	t.SetTitle("RBAC pre-flight")
+
+	// Only the actions that were checked get a column
+	var columns []lib.PermissionCheck
+	for _, column := range lib.PermissionColumns {
+		for _, permission := range permissions {
+			if permission.Check.Name() == column.Name() {
+				columns = append(columns, column)
+				break
+			}
+		}
+	}
+
+	// Resources in the first header row, merged over their verbs in the second
+	resourceRow := table.Row{"CLUSTER", "NAMESPACE"}
+	verbRow := table.Row{"", ""}
+	for _, column := range columns {
+		resourceRow = append(resourceRow, column.Resource)
+		verbRow = append(verbRow, column.Verb)
+	}
+	t.AppendHeader(resourceRow, rowConfigAutoMerge)
+	t.AppendHeader(verbRow)
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, AutoMerge: true},
+	})
+
+	// One row per cluster and namespace, in the order in which they were checked
+	var keys [][2]string
+	cells := make(map[[2]string]map[string]lib.Permission)
+	for _, permission := range permissions {
+		key := [2]string{permission.Cluster, permission.Namespace}
+		if _, ok := cells[key]; !ok {
+			keys = append(keys, key)
+			cells[key] = make(map[string]lib.Permission)
+		}
+		cells[key][permission.Check.Name()] = permission
+	}
+
+	for _, key := range keys {
+		namespace := key[1]
+		if namespace == "" {
+			namespace = "(all)"
+		}
+		row := table.Row{key[0], namespace}
+		for _, column := range columns {
+			permission, ok := cells[key][column.Name()]
+			row = append(row, getPermissionText(permission, ok))
+		}
+		t.AppendRow(row)
+	}
+
+	// Render the table
+	t.Render()
+}
+
+// getPermissionText shows an allowed action as yes, a denied one in red or in yellow when it is optional
+func getPermissionText(permission lib.Permission, checked bool) string {
+	switch {
+	case !checked:
+		return "-"
+	case permission.Allowed:
+		return text.FgGreen.Sprint("yes")
+	case permission.Check.Required:
+		return text.FgRed.Sprint("no")
+	default:
+		return text.FgYellow.Sprint("no")
+	}
+}
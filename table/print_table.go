@@ -14,7 +14,6 @@ package table
 import (
 	"fmt"
 	"github.com/Einic/cops/lib"
-	AlterResource "github.com/Einic/cops/resources"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
 	"os"
@@ -23,9 +22,136 @@ import (
 	"unicode"
 )
 
+// updateHeader is the header of the update table, shared by the table and markdown output
+var updateHeader = table.Row{"DataTime", "LINE", "CLUSTER", "WORKLOAD", "CONTAINERNAME", "CONTAINERKIND", "WORKTYPE", "NAMESPACE", "Replicas", "Requests (CPU)", "Requests (Memory)", "Limits (CPU)", "Limits (Memory)", "Other Resources", "PodQos", "RUNSTATUS", "ALTERSTATUS", "REASON"}
+
 func PrintUpdateTable(updateSlice []lib.ResourceInfo) {
 	// Create a new table
 	rowConfigAutoMerge := table.RowConfig{AutoMerge: true}
+	t := newTableWriter()
+
+	// Append the header row with bold formatting
+	t.AppendHeader(updateHeader, rowConfigAutoMerge)
+
+	// Customize the table
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "CLUSTER", AutoMerge: true},
+		{Name: "Replicas", Transformer: transformReplicas},
+		{Name: "Requests (CPU)", Transformer: transformColorfulValue},
+		{Name: "Requests (Memory)", Transformer: transformColorfulValue},
+		{Name: "Limits (CPU)", Transformer: transformColorfulValue},
+		{Name: "Limits (Memory)", Transformer: transformColorfulValue},
+		{Name: "RUNSTATUS", Transformer: transformStatus},
+		{Name: "ALTERSTATUS", Transformer: transformStatus},
+	})
+
+	// Append rows for each update
+	for _, update := range updateSlice {
+		t.AppendSeparator()
+		t.AppendRow(updateRow(update))
+	}
+
+	// Summary of the row statuses below the table
+	t.SetCaption(summaryCaption(updateSlice))
+
+	// Render the table
+	t.Render()
+}
+
+// updateRow renders an update as plain text, the colors are added by the column transformers.
+// Rows that were not applied have no run status and show no values.
+func updateRow(update lib.ResourceInfo) table.Row {
+	if update.RunStatus == "" {
+		return table.Row{
+			update.DataTime.Format("2006-01-02 15:04:05"),
+			update.Line,
+			update.Cluster,
+			update.Workload,
+			update.ContainerName,
+			update.ContainerKind,
+			update.WorkType,
+			update.Namespace,
+			"-", "-", "-", "-", "-", "-", "-", "-",
+			update.AlterStatus,
+			update.Reason,
+		}
+	}
+
+	return table.Row{
+		update.DataTime.Format("2006-01-02 15:04:05"),
+		update.Line,
+		update.Cluster,
+		update.Workload,
+		update.ContainerName,
+		update.ContainerKind,
+		update.WorkType,
+		update.Namespace,
+		fmt.Sprintf("%d -> %d", update.CurrentReplicas, update.AlterReplicas),
+		formatChange(update.CurrentRequestsCPU, update.AlterRequestsCPU),
+		formatChange(update.CurrentRequestsMemory, update.AlterRequestsMemory),
+		formatChange(update.CurrentLimitsCPU, update.AlterLimitsCPU),
+		formatChange(update.CurrentLimitsMemory, update.AlterLimitsMemory),
+		formatOtherResources(update.OtherResources),
+		formatChange(update.CurrentPodQos, update.PodQos),
+		update.RunStatus,
+		update.AlterStatus,
+		update.Reason,
+	}
+}
+
+// summaryCaption counts the rows per alter status
+func summaryCaption(updateSlice []lib.ResourceInfo) string {
+	counts := make(map[string]int)
+	for _, update := range updateSlice {
+		counts[update.AlterStatus]++
+	}
+
+	parts := make([]string, 0, len(lib.AlterStatuses)+1)
+	parts = append(parts, fmt.Sprintf("Total: %d", len(updateSlice)))
+	for _, status := range lib.AlterStatuses {
+		parts = append(parts, fmt.Sprintf("%s: %d", status, counts[status]))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// transformStatus colors the run and alter status
+func transformStatus(data interface{}) string {
+	status := fmt.Sprintf("%v", data)
+	switch status {
+	case lib.AlterStatusSuccess, lib.RunStatusAvailable:
+		return text.FgGreen.Sprint(status)
+	case lib.RunStatusPartialAvailable, lib.AlterStatusSkipped, lib.AlterStatusInvalid:
+		return text.FgYellow.Sprint(status)
+	case lib.AlterStatusFailed, lib.RunStatusNotAvailable:
+		return text.FgRed.Sprint(status)
+	default:
+		return status
+	}
+}
+
+// formatChange renders a before and after value, a resource that is not set is shown as (none)
+func formatChange(current, alter string) string {
+	return fmt.Sprintf("%s -> %s", noneIfEmpty(current), noneIfEmpty(alter))
+}
+
+func noneIfEmpty(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+// formatOtherResources renders the other named resources of a container, one per line
+func formatOtherResources(otherResources []lib.ResourceChange) string {
+	lines := make([]string, 0, len(otherResources))
+	for _, other := range otherResources {
+		lines = append(lines, fmt.Sprintf("%s: %s", other.Name, formatChange(other.Current, other.Alter)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newTableWriter creates a table writer with the bold style shared by all cops tables
+func newTableWriter() table.Writer {
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
 	// Customize the table header style
@@ -48,43 +174,7 @@ func PrintUpdateTable(updateSlice []lib.ResourceInfo) {
 	}
 	t.SetStyle(BoldStyle)
 	t.SetAutoIndex(true)
-
-	// Append the header row with bold formatting
-	headerRow := table.Row{"DataTime", "WORKLOAD", "CONTAINERNAME", "WORKTYPE", "NAMESPACE", "Replicas", "Requests (CPU)", "Requests (Memory)", "Limits (CPU)", "Limits (Memory)", "PodQos", "RUNSTATUS", "ALTERSTATUS"}
-	// Set the color and style for the header row
-	t.AppendHeader(headerRow, rowConfigAutoMerge)
-
-	// Customize the table
-	t.SetColumnConfigs([]table.ColumnConfig{
-		{Name: "Replicas", Transformer: transformReplicas},
-		{Name: "Requests (CPU)", Transformer: transformColorfulValue},
-		{Name: "Requests (Memory)", Transformer: transformColorfulValue},
-		{Name: "Limits (CPU)", Transformer: transformColorfulValue},
-		{Name: "Limits (Memory)", Transformer: transformColorfulValue},
-	})
-
-	// Append rows for each update
-	for _, update := range updateSlice {
-		t.AppendSeparator()
-		t.AppendRow([]interface{}{
-			update.DataTime,
-			update.Workload,
-			update.ContainerName,
-			update.WorkType,
-			update.Namespace,
-			fmt.Sprintf("%d -> %d", update.CurrentReplicas, update.AlterReplicas),
-			fmt.Sprintf("%s -> %s", update.CurrentRequestsCPU, update.AlterRequestsCPU),
-			fmt.Sprintf("%s -> %s", update.CurrentRequestsMemory, update.AlterRequestsMemory),
-			fmt.Sprintf("%s -> %s", update.CurrentLimitsCPU, update.AlterLimitsCPU),
-			fmt.Sprintf("%s -> %s", update.CurrentLimitsMemory, update.AlterLimitsMemory),
-			update.PodQos,
-			update.RunStatus,
-			AlterResource.GetStatusText(update.AlterStatus),
-		})
-	}
-
-	// Render the table
-	t.Render()
+	return t
 }
 
 // Custom transformer for Replicas column
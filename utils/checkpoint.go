@@ -0,0 +1,144 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: checkpoint
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 20:22
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Checkpoint records every row as soon as it is applied, so that an interrupted run can be resumed
+type Checkpoint struct {
+	mu          sync.Mutex
+	file        *os.File
+	recordHash  map[int]string
+	completed   map[string]bool
+	resumedRows int
+}
+
+// CheckpointPath returns the path of the checkpoint next to the change file, e.g. example.checkpoint.jsonl
+func CheckpointPath(csvPath string) string {
+	return strings.TrimSuffix(csvPath, filepath.Ext(csvPath)) + ".checkpoint.jsonl"
+}
+
+// OpenCheckpoint opens the checkpoint of the change file. With resume the rows recorded by an earlier run
+// are loaded, otherwise the checkpoint starts empty.
+func OpenCheckpoint(path string, resume bool, lines [][]string, lineNumbers []int) (*Checkpoint, error) {
+	checkpoint := &Checkpoint{
+		recordHash: make(map[int]string),
+		completed:  make(map[string]bool),
+	}
+	for i, line := range lines {
+		checkpoint.recordHash[lineNumbers[i]] = recordHash(line)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		if err := checkpoint.load(path); err != nil {
+			return nil, err
+		}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening checkpoint %s: %v", path, err)
+	}
+	checkpoint.file = file
+	return checkpoint, nil
+}
+
+// load reads the entries of an earlier run, entries of rows that were edited since then are ignored
+func (c *Checkpoint) load(path string) error {
+	err := readJSONLines(path, func(entry lib.CheckpointEntry) {
+		if c.recordHash[entry.Line] == entry.RecordHash {
+			c.completed[checkpointKey(entry.Cluster, entry.Namespace, entry.WorkType, entry.Workload, entry.Line)] = true
+			c.resumedRows++
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("error reading checkpoint %s: %v", path, err)
+	}
+	return nil
+}
+
+// Completed reports whether the row was already applied to the workload by an earlier run
+func (c *Checkpoint) Completed(cluster string, row lib.ChangeRow) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.completed[checkpointKey(cluster, row.Namespace, row.WorkType, row.Workload, row.Line)]
+}
+
+// Complete records the rows of a workload change that was applied and syncs the checkpoint to disk
+func (c *Checkpoint) Complete(cluster string, change lib.WorkloadChange) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, container := range change.Containers {
+		entry := lib.CheckpointEntry{
+			Cluster:     cluster,
+			Namespace:   change.Namespace,
+			WorkType:    change.WorkType,
+			Workload:    change.Workload,
+			Line:        container.Line,
+			RecordHash:  c.recordHash[container.Line],
+			CompletedAt: time.Now(),
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := c.file.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("error writing checkpoint: %v", err)
+		}
+		c.completed[checkpointKey(entry.Cluster, entry.Namespace, entry.WorkType, entry.Workload, entry.Line)] = true
+	}
+	return c.file.Sync()
+}
+
+// ResumedRows returns the number of applied rows loaded from an earlier run
+func (c *Checkpoint) ResumedRows() int {
+	return c.resumedRows
+}
+
+// Close closes the checkpoint file
+func (c *Checkpoint) Close() error {
+	return c.file.Close()
+}
+
+// Remove closes and deletes the checkpoint file, once the run no longer needs to be resumed
+func (c *Checkpoint) Remove() error {
+	path := c.file.Name()
+	_ = c.file.Close()
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing checkpoint %s: %v", path, err)
+	}
+	return nil
+}
+
+func checkpointKey(cluster, namespace, workType, workload string, line int) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%d", cluster, namespace, workType, workload, line)
+}
+
+// recordHash identifies the content of a CSV record
+func recordHash(record []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(record, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Einic/cops/lib"
+)
+
+func TestCheckpointPath(t *testing.T) {
+	if got, want := CheckpointPath("changes/example.csv"), "changes/example.checkpoint.jsonl"; got != want {
+		t.Errorf("CheckpointPath() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	lines := [][]string{
+		{"web", "web", "deployment", "shop", "2", "500m", "1Gi", "250m", "512Mi"},
+		{"web", "proxy", "deployment", "shop", "2", "100m", "128Mi", "50m", "64Mi"},
+		{"api", "api", "deployment", "shop", "1", "1000m", "2Gi", "500m", "1Gi"},
+	}
+	lineNumbers := []int{2, 3, 4}
+	change := lib.WorkloadChange{
+		Workload:  "web",
+		WorkType:  "deployment",
+		Namespace: "shop",
+		Containers: []lib.ContainerChange{
+			{ContainerName: "web", Line: 2},
+			{ContainerName: "proxy", Line: 3},
+		},
+	}
+	row := func(workload string, line int) lib.ChangeRow {
+		return lib.ChangeRow{Workload: workload, WorkType: "deployment", Namespace: "shop", Line: line}
+	}
+
+	// completeRun records the web rows on cluster a, as an interrupted run would
+	completeRun := func(t *testing.T, path string) {
+		t.Helper()
+		checkpoint, err := OpenCheckpoint(path, false, lines, lineNumbers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkpoint.Complete("a", change); err != nil {
+			t.Fatal(err)
+		}
+		if err := checkpoint.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		resume      bool
+		prepare     func(t *testing.T, path string)
+		lines       [][]string
+		wantResumed int
+		want        map[int]bool
+	}{
+		{
+			name:        "resume loads the applied rows",
+			resume:      true,
+			wantResumed: 2,
+			want:        map[int]bool{2: true, 3: true, 4: false},
+		},
+		{
+			name:        "without resume the checkpoint starts empty",
+			resume:      false,
+			wantResumed: 0,
+			want:        map[int]bool{2: false, 3: false, 4: false},
+		},
+		{
+			name:   "a torn last line is skipped",
+			resume: true,
+			prepare: func(t *testing.T, path string) {
+				file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer file.Close()
+				if _, err := file.WriteString(`{"cluster":"a","namespace":"shop","workType":"deploy`); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantResumed: 2,
+			want:        map[int]bool{2: true, 3: true, 4: false},
+		},
+		{
+			name:   "a row edited since the checkpoint is applied again",
+			resume: true,
+			lines: [][]string{
+				lines[0],
+				{"web", "proxy", "deployment", "shop", "2", "200m", "128Mi", "50m", "64Mi"},
+				lines[2],
+			},
+			wantResumed: 1,
+			want:        map[int]bool{2: true, 3: false, 4: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "example.checkpoint.jsonl")
+			completeRun(t, path)
+			if tt.prepare != nil {
+				tt.prepare(t, path)
+			}
+
+			runLines := lines
+			if tt.lines != nil {
+				runLines = tt.lines
+			}
+			checkpoint, err := OpenCheckpoint(path, tt.resume, runLines, lineNumbers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer checkpoint.Close()
+
+			if got := checkpoint.ResumedRows(); got != tt.wantResumed {
+				t.Errorf("ResumedRows() = %d, want %d", got, tt.wantResumed)
+			}
+			workloads := map[int]string{2: "web", 3: "web", 4: "api"}
+			for line, want := range tt.want {
+				if got := checkpoint.Completed("a", row(workloads[line], line)); got != want {
+					t.Errorf("Completed(line %d) = %v, want %v", line, got, want)
+				}
+			}
+			// Rows are recorded per cluster
+			if checkpoint.Completed("b", row("web", 2)) {
+				t.Error("Completed() on another cluster = true, want false")
+			}
+		})
+	}
+}
+
+func TestCheckpointResumeWithoutFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "example.checkpoint.jsonl")
+	checkpoint, err := OpenCheckpoint(path, true, [][]string{{"web"}}, []int{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer checkpoint.Close()
+	if got := checkpoint.ResumedRows(); got != 0 {
+		t.Errorf("ResumedRows() = %d, want 0", got)
+	}
+}
@@ -0,0 +1,108 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: drift_csv
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 15:21
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	"github.com/Einic/cops/zaplog"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"os"
+	"strconv"
+)
+
+// WriteDriftCSV writes a change file that aligns the target cluster with the source cluster.
+// Only drifted containers are written, and it returns the number of rows written.
+func WriteDriftCSV(csvPath string, drifts []lib.DriftInfo, toCluster string, logger zaplog.Logger) (int, error) {
+	file, err := os.Create(csvPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(append(append([]string{}, lib.RequiredColumns...), lib.ColumnCluster)); err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for _, drift := range drifts {
+		if drift.DriftStatus != lib.DriftStatusDrift {
+			continue
+		}
+
+		limitsCPU, errLimitsCPU := formatMilliCPU(drift.FromLimitsCPU)
+		limitsMemory, errLimitsMemory := formatMegaMemory(drift.FromLimitsMemory)
+		requestsCPU, errRequestsCPU := formatMilliCPU(drift.FromRequestsCPU)
+		requestsMemory, errRequestsMemory := formatMegaMemory(drift.FromRequestsMemory)
+		if err := firstError(errLimitsCPU, errLimitsMemory, errRequestsCPU, errRequestsMemory); err != nil {
+			logger.Warn("Skipping drifted container that cannot be written to the change file", zap.String("WorkLoad", drift.Workload), zap.String("ContainerName", drift.ContainerName), zap.String("Namespace", drift.Namespace), zap.Error(err))
+			continue
+		}
+
+		// Columns follow lib.RequiredColumns, with the target cluster appended
+		if err := writer.Write([]string{
+			drift.Workload,
+			drift.ContainerName,
+			drift.WorkType,
+			drift.Namespace,
+			strconv.Itoa(drift.FromReplicas),
+			limitsCPU,
+			limitsMemory,
+			requestsCPU,
+			requestsMemory,
+			toCluster,
+		}); err != nil {
+			return written, err
+		}
+		written++
+	}
+
+	writer.Flush()
+	return written, writer.Error()
+}
+
+// formatMilliCPU formats a CPU quantity in milli-units as required by the change file
+func formatMilliCPU(value string) (string, error) {
+	if value == "" {
+		return "none", nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%dm", quantity.MilliValue()), nil
+}
+
+// formatMegaMemory formats a memory quantity in Mebibytes as required by the change file, rounding up
+func formatMegaMemory(value string) (string, error) {
+	if value == "" {
+		return "none", nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return "", err
+	}
+	const mebibyte = 1024 * 1024
+	return fmt.Sprintf("%dMi", (quantity.Value()+mebibyte-1)/mebibyte), nil
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
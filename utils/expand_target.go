@@ -0,0 +1,164 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: expand_target
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 16:10
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	AlterResource "github.com/Einic/cops/resources"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"path"
+	"strings"
+)
+
+// IsPattern checks if a value is a glob pattern such as '*' or 'team-*'.
+func IsPattern(value string) bool {
+	return strings.ContainsAny(value, "*?[")
+}
+
+// IsLabelSelector checks if a workload value is a label selector such as 'tier=frontend'.
+func IsLabelSelector(value string) bool {
+	return strings.ContainsAny(value, "=!(")
+}
+
+// ExpandChangeRow expands a row that targets a namespace glob, a workload glob or a label selector
+// into one row per matching Deployment/StatefulSet. Rows naming a single workload are returned as is.
+func ExpandChangeRow(ctx context.Context, clientset *kubernetes.Clientset, row lib.ChangeRow) ([]lib.ChangeRow, error) {
+	namespacePattern := IsPattern(row.Namespace)
+	workloadSelector := IsLabelSelector(row.Workload)
+	workloadPattern := !workloadSelector && IsPattern(row.Workload)
+
+	if !namespacePattern && !workloadSelector && !workloadPattern {
+		return []lib.ChangeRow{row}, nil
+	}
+
+	// Check the patterns up front so that a typo is reported instead of matching nothing
+	if _, err := path.Match(row.Namespace, ""); err != nil {
+		return nil, fmt.Errorf("invalid namespace pattern %q: %v", row.Namespace, err)
+	}
+
+	labelSelector := ""
+	if workloadSelector {
+		if _, err := labels.Parse(row.Workload); err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %v", row.Workload, err)
+		}
+		labelSelector = row.Workload
+	} else if _, err := path.Match(row.Workload, ""); err != nil {
+		return nil, fmt.Errorf("invalid workload pattern %q: %v", row.Workload, err)
+	}
+
+	namespaces := []string{row.Namespace}
+	if namespacePattern {
+		allNamespaces, err := AlterResource.ListNamespaceNames(ctx, clientset)
+		if err != nil {
+			return nil, fmt.Errorf("error listing namespaces: %v", err)
+		}
+		namespaces = matchNames(allNamespaces, row.Namespace)
+	}
+
+	var rows []lib.ChangeRow
+	for _, namespace := range namespaces {
+		// A plain workload name only matches the namespaces in which it exists
+		workloads, err := AlterResource.ListWorkloadNames(ctx, clientset, row.WorkType, namespace, labelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("error listing %s in namespace %s: %v", row.WorkType, namespace, err)
+		}
+		if !workloadSelector {
+			workloads = matchNames(workloads, row.Workload)
+		}
+
+		for _, workload := range workloads {
+			expanded := row
+			expanded.Namespace = namespace
+			expanded.Workload = workload
+			rows = append(rows, expanded)
+		}
+	}
+
+	return rows, nil
+}
+
+// matchNames returns the names matching a glob pattern, the pattern must already be validated
+func matchNames(names []string, pattern string) []string {
+	var matched []string
+	for _, name := range names {
+		if ok, _ := path.Match(pattern, name); ok {
+			matched = append(matched, name)
+		}
+	}
+	return matched
+}
+
+// RowError is the error of a single change row
+type RowError struct {
+	Row lib.ChangeRow
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return e.Err.Error()
+}
+
+// GroupChangeRows merges the rows targeting the same workload into one WorkloadChange, keeping the
+// order in which the workloads first appear. Rows that conflict with an earlier row of the same
+// workload are left out and reported as RowErrors.
+func GroupChangeRows(rows []lib.ChangeRow) ([]lib.WorkloadChange, []error) {
+	var changes []lib.WorkloadChange
+	var errs []error
+	index := make(map[string]int)
+
+	for _, row := range rows {
+		container := lib.ContainerChange{
+			ContainerName:  row.ContainerName,
+			ContainerKind:  row.ContainerKind,
+			LimitsCPU:      row.LimitsCPU,
+			LimitsMemory:   row.LimitsMemory,
+			RequestsCPU:    row.RequestsCPU,
+			RequestsMemory: row.RequestsMemory,
+			OtherLimits:    row.OtherLimits,
+			OtherRequests:  row.OtherRequests,
+			Line:           row.Line,
+		}
+
+		key := row.WorkType + "/" + row.Namespace + "/" + row.Workload
+		i, found := index[key]
+		if !found {
+			index[key] = len(changes)
+			changes = append(changes, lib.WorkloadChange{
+				Workload:   row.Workload,
+				WorkType:   row.WorkType,
+				Namespace:  row.Namespace,
+				Replicas:   row.Replicas,
+				TargetQoS:  row.TargetQoS,
+				Containers: []lib.ContainerChange{container},
+			})
+			continue
+		}
+
+		if changes[i].Replicas != row.Replicas {
+			errs = append(errs, &RowError{Row: row, Err: fmt.Errorf("%s %s in namespace %s: replicas %s conflicts with replicas %s of an earlier row", row.WorkType, row.Workload, row.Namespace, row.Replicas, changes[i].Replicas)})
+			continue
+		}
+		if row.TargetQoS != "" && changes[i].TargetQoS != "" && changes[i].TargetQoS != row.TargetQoS {
+			errs = append(errs, &RowError{Row: row, Err: fmt.Errorf("%s %s in namespace %s: target_qos %s conflicts with target_qos %s of an earlier row", row.WorkType, row.Workload, row.Namespace, row.TargetQoS, changes[i].TargetQoS)})
+			continue
+		}
+		if row.TargetQoS != "" {
+			changes[i].TargetQoS = row.TargetQoS
+		}
+		changes[i].Containers = append(changes[i].Containers, container)
+	}
+
+	return changes, errs
+}
@@ -0,0 +1,328 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Einic/cops/lib"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// testWorkload is a Deployment or StatefulSet served by newTestClientset
+type testWorkload struct {
+	kind      string
+	namespace string
+	name      string
+	labels    map[string]string
+}
+
+// newTestClientset returns a clientset for an API server that lists the namespaces and workloads,
+// filtered by the label selector of the request
+func newTestClientset(t *testing.T, namespaces []string, workloads []testWorkload) *kubernetes.Clientset {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		selector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var list interface{}
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case r.URL.Path == "/api/v1/namespaces":
+			namespaceList := &corev1.NamespaceList{TypeMeta: metav1.TypeMeta{Kind: "NamespaceList", APIVersion: "v1"}}
+			for _, namespace := range namespaces {
+				namespaceList.Items = append(namespaceList.Items, corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})
+			}
+			list = namespaceList
+		case len(parts) == 6 && parts[5] == "deployments":
+			deploymentList := &appsv1.DeploymentList{TypeMeta: metav1.TypeMeta{Kind: "DeploymentList", APIVersion: "apps/v1"}}
+			for _, workload := range workloads {
+				if workload.kind == "deployment" && workload.namespace == parts[4] && selector.Matches(labels.Set(workload.labels)) {
+					deploymentList.Items = append(deploymentList.Items, appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: workload.name, Namespace: workload.namespace, Labels: workload.labels}})
+				}
+			}
+			list = deploymentList
+		case len(parts) == 6 && parts[5] == "statefulsets":
+			statefulSetList := &appsv1.StatefulSetList{TypeMeta: metav1.TypeMeta{Kind: "StatefulSetList", APIVersion: "apps/v1"}}
+			for _, workload := range workloads {
+				if workload.kind == "statefulset" && workload.namespace == parts[4] && selector.Matches(labels.Set(workload.labels)) {
+					statefulSetList.Items = append(statefulSetList.Items, appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: workload.name, Namespace: workload.namespace, Labels: workload.labels}})
+				}
+			}
+			list = statefulSetList
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(list)
+	}))
+	t.Cleanup(server.Close)
+
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clientset
+}
+
+// targets returns the namespace/workload of the rows
+func targets(rows []lib.ChangeRow) []string {
+	var names []string
+	for _, row := range rows {
+		names = append(names, row.Namespace+"/"+row.Workload)
+	}
+	return names
+}
+
+func TestIsPattern(t *testing.T) {
+	tests := []struct {
+		value       string
+		pattern     bool
+		selector    bool
+		description string
+	}{
+		{"web", false, false, "literal name"},
+		{"team-*", true, false, "star"},
+		{"web-?", true, false, "question mark"},
+		{"web-[ab]", true, false, "character class"},
+		{"tier=frontend", false, true, "equality selector"},
+		{"tier!=frontend", false, true, "inequality selector"},
+		{"tier in (frontend,backend)", false, true, "set selector"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			if got := IsPattern(tt.value); got != tt.pattern {
+				t.Errorf("IsPattern(%q) = %v, want %v", tt.value, got, tt.pattern)
+			}
+			if got := IsLabelSelector(tt.value); got != tt.selector {
+				t.Errorf("IsLabelSelector(%q) = %v, want %v", tt.value, got, tt.selector)
+			}
+		})
+	}
+}
+
+func TestMatchNames(t *testing.T) {
+	names := []string{"team-a", "team-b", "shop", "team-ab"}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"team-*", []string{"team-a", "team-b", "team-ab"}},
+		{"team-?", []string{"team-a", "team-b"}},
+		{"team-[a]", []string{"team-a"}},
+		{"shop", []string{"shop"}},
+		{"none-*", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := matchNames(names, tt.pattern); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchNames(%q) = %v, want %v", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandChangeRow(t *testing.T) {
+	clientset := newTestClientset(t, []string{"team-a", "team-b", "shop"}, []testWorkload{
+		{"deployment", "team-a", "api", map[string]string{"tier": "backend"}},
+		{"deployment", "team-a", "web", map[string]string{"tier": "frontend"}},
+		{"deployment", "team-b", "api", map[string]string{"tier": "backend"}},
+		{"deployment", "shop", "web", map[string]string{"tier": "frontend"}},
+		{"statefulset", "team-a", "db", map[string]string{"tier": "backend"}},
+	})
+
+	tests := []struct {
+		name    string
+		row     lib.ChangeRow
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "literal row is kept as is",
+			row:  lib.ChangeRow{Workload: "missing", WorkType: "deployment", Namespace: "shop"},
+			want: []string{"shop/missing"},
+		},
+		{
+			name: "workload glob",
+			row:  lib.ChangeRow{Workload: "a*", WorkType: "deployment", Namespace: "team-a"},
+			want: []string{"team-a/api"},
+		},
+		{
+			name: "namespace glob keeps the namespaces where the workload exists",
+			row:  lib.ChangeRow{Workload: "web", WorkType: "deployment", Namespace: "*"},
+			want: []string{"team-a/web", "shop/web"},
+		},
+		{
+			name: "namespace and workload glob",
+			row:  lib.ChangeRow{Workload: "*", WorkType: "deployment", Namespace: "team-*"},
+			want: []string{"team-a/api", "team-a/web", "team-b/api"},
+		},
+		{
+			name: "label selector",
+			row:  lib.ChangeRow{Workload: "tier=frontend", WorkType: "deployment", Namespace: "shop"},
+			want: []string{"shop/web"},
+		},
+		{
+			name: "label selector over a namespace glob",
+			row:  lib.ChangeRow{Workload: "tier=backend", WorkType: "deployment", Namespace: "team-*"},
+			want: []string{"team-a/api", "team-b/api"},
+		},
+		{
+			name: "statefulsets",
+			row:  lib.ChangeRow{Workload: "tier=backend", WorkType: "statefulset", Namespace: "team-a"},
+			want: []string{"team-a/db"},
+		},
+		{
+			name: "nothing matches",
+			row:  lib.ChangeRow{Workload: "cache-*", WorkType: "deployment", Namespace: "team-a"},
+			want: nil,
+		},
+		{
+			name:    "invalid namespace pattern",
+			row:     lib.ChangeRow{Workload: "api", WorkType: "deployment", Namespace: "team-["},
+			wantErr: true,
+		},
+		{
+			name:    "invalid workload pattern",
+			row:     lib.ChangeRow{Workload: "api-[", WorkType: "deployment", Namespace: "team-*"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid label selector",
+			row:     lib.ChangeRow{Workload: "tier in (backend", WorkType: "deployment", Namespace: "team-a"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ExpandChangeRow(context.Background(), clientset, tt.row)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandChangeRow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := targets(rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandChangeRow() = %v, want %v", got, tt.want)
+			}
+			for _, row := range rows {
+				if row.WorkType != tt.row.WorkType {
+					t.Errorf("expanded row has worktype %s, want %s", row.WorkType, tt.row.WorkType)
+				}
+			}
+		})
+	}
+}
+
+// changeSummary describes a WorkloadChange as worktype/namespace/workload, its replicas, containers and target QoS
+func changeSummary(change lib.WorkloadChange) string {
+	var containers []string
+	for _, container := range change.Containers {
+		containers = append(containers, container.ContainerName)
+	}
+	summary := change.WorkType + "/" + change.Namespace + "/" + change.Workload + " replicas=" + change.Replicas + " containers=" + strings.Join(containers, ",")
+	if change.TargetQoS != "" {
+		summary += " qos=" + change.TargetQoS
+	}
+	return summary
+}
+
+func TestGroupChangeRows(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     []lib.ChangeRow
+		want     []string
+		wantErrs int
+	}{
+		{
+			name: "rows of different workloads stay apart",
+			rows: []lib.ChangeRow{
+				{Workload: "web", WorkType: "deployment", Namespace: "shop", Replicas: "2", ContainerName: "web"},
+				{Workload: "web", WorkType: "statefulset", Namespace: "shop", Replicas: "2", ContainerName: "web"},
+				{Workload: "web", WorkType: "deployment", Namespace: "team-a", Replicas: "2", ContainerName: "web"},
+			},
+			want: []string{
+				"deployment/shop/web replicas=2 containers=web",
+				"statefulset/shop/web replicas=2 containers=web",
+				"deployment/team-a/web replicas=2 containers=web",
+			},
+		},
+		{
+			name: "rows of the same workload are merged in order",
+			rows: []lib.ChangeRow{
+				{Workload: "web", WorkType: "deployment", Namespace: "shop", Replicas: "2", ContainerName: "web"},
+				{Workload: "api", WorkType: "deployment", Namespace: "shop", Replicas: "1", ContainerName: "api"},
+				{Workload: "web", WorkType: "deployment", Namespace: "shop", Replicas: "2", ContainerName: "proxy"},
+			},
+			want: []string{
+				"deployment/shop/web replicas=2 containers=web,proxy",
+				"deployment/shop/api replicas=1 containers=api",
+			},
+		},
+		{
+			name: "duplicate targets are kept for the update to reject",
+			rows: []lib.ChangeRow{
+				{Workload: "web", WorkType: "deployment", Namespace: "shop", Replicas: "2", ContainerName: "web"},
+				{Workload: "web", WorkType: "deployment", Namespace: "shop", Replicas: "2", ContainerName: "web"},
+			},
+			want: []string{"deployment/shop/web replicas=2 containers=web,web"},
+		},
+		{
+			name: "conflicting replicas are left out",
+			rows: []lib.ChangeRow{
+				{Workload: "web", WorkType: "deployment", Namespace: "shop", Replicas: "2", ContainerName: "web"},
+				{Workload: "web", WorkType: "deployment", Namespace: "shop", Replicas: "3", ContainerName: "proxy"},
+			},
+			want:     []string{"deployment/shop/web replicas=2 containers=web"},
+			wantErrs: 1,
+		},
+		{
+			name: "target_qos of a later row is kept",
+			rows: []lib.ChangeRow{
+				{Workload: "web", WorkType: "deployment", Namespace: "shop", Replicas: "2", ContainerName: "web"},
+				{Workload: "web", WorkType: "deployment", Namespace: "shop", Replicas: "2", ContainerName: "proxy", TargetQoS: "Guaranteed"},
+			},
+			want: []string{"deployment/shop/web replicas=2 containers=web,proxy qos=Guaranteed"},
+		},
+		{
+			name: "conflicting target_qos is left out",
+			rows: []lib.ChangeRow{
+				{Workload: "web", WorkType: "deployment", Namespace: "shop", Replicas: "2", ContainerName: "web", TargetQoS: "Guaranteed"},
+				{Workload: "web", WorkType: "deployment", Namespace: "shop", Replicas: "2", ContainerName: "proxy", TargetQoS: "Burstable"},
+			},
+			want:     []string{"deployment/shop/web replicas=2 containers=web qos=Guaranteed"},
+			wantErrs: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, errs := GroupChangeRows(tt.rows)
+			var got []string
+			for _, change := range changes {
+				got = append(got, changeSummary(change))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GroupChangeRows() = %v, want %v", got, tt.want)
+			}
+			if len(errs) != tt.wantErrs {
+				t.Errorf("GroupChangeRows() errors = %v, want %d", errs, tt.wantErrs)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: failed_csv
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 19:52
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package utils
+
+import (
+	"encoding/csv"
+	"github.com/Einic/cops/lib"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FailedCSVPath returns the path of the retry file next to the change file, e.g. example.failed.csv
+func FailedCSVPath(csvPath string) string {
+	return strings.TrimSuffix(csvPath, filepath.Ext(csvPath)) + ".failed.csv"
+}
+
+// WriteFailedCSV writes the rows that failed or are invalid to a change file with the original header.
+// Invalid rows are written as they are. Rows expanded from globs or label selectors are narrowed to the
+// workloads that failed, so that a re-run does not touch the workloads that were already changed.
+// When the run spans several clusters the rows are also narrowed to their cluster, adding a cluster
+// column if the change file has none. A retry file of an earlier run is removed when nothing failed.
+func WriteFailedCSV(csvPath string, header []string, lines [][]string, lineNumbers []int, updates []lib.ResourceInfo) (int, error) {
+	records := make(map[int][]string)
+	for i, line := range lines {
+		records[lineNumbers[i]] = line
+	}
+
+	// A row applied to several clusters may only have failed on some of them
+	clusters := make(map[string]bool)
+	for _, update := range updates {
+		if update.Cluster != "" {
+			clusters[update.Cluster] = true
+		}
+	}
+	addCluster := len(clusters) > 1 && columnIndex(header, lib.ColumnCluster) < 0
+	if addCluster {
+		header = append(append([]string(nil), header...), lib.ColumnCluster)
+	}
+
+	type failedRow struct {
+		line   int
+		record []string
+	}
+	var rows []failedRow
+	written := make(map[string]bool)
+
+	for _, update := range updates {
+		if update.AlterStatus != lib.AlterStatusFailed && update.AlterStatus != lib.AlterStatusInvalid {
+			continue
+		}
+		record, found := records[update.Line]
+		if !found {
+			continue
+		}
+		record = append([]string(nil), record...)
+		if addCluster && len(record) == len(header)-1 {
+			record = append(record, "")
+		}
+
+		// Narrow the row to the failed workload, the containers of a workload always fail together
+		if len(record) == len(header) {
+			if update.AlterStatus == lib.AlterStatusFailed {
+				setColumn(header, record, lib.ColumnWorkload, update.Workload)
+				setColumn(header, record, lib.ColumnNamespace, update.Namespace)
+			}
+			setColumn(header, record, lib.ColumnCluster, update.Cluster)
+		}
+
+		key := strings.Join(record, "\x00")
+		if written[key] {
+			continue
+		}
+		written[key] = true
+		rows = append(rows, failedRow{line: update.Line, record: record})
+	}
+
+	if len(rows) == 0 {
+		if err := os.Remove(csvPath); err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+		return 0, nil
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].line < rows[j].line })
+
+	file, err := os.Create(csvPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(header); err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		if err := writer.Write(row.record); err != nil {
+			return 0, err
+		}
+	}
+	writer.Flush()
+	return len(rows), writer.Error()
+}
+
+// setColumn sets a column of the record when the header has it and the value is known
+func setColumn(header, record []string, column, value string) {
+	if i := columnIndex(header, column); i >= 0 && value != "" {
+		record[i] = value
+	}
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Einic/cops/lib"
+)
+
+func readRetryFile(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestWriteFailedCSV(t *testing.T) {
+	header := []string{"Workload", "Containers_Name", "WorkType", "Namespace", "Replicas", "Limits_CPU", "Limits_Memory", "Requests_CPU", "Requests_Memory"}
+	lines := [][]string{
+		{"api-*", "*", "deployment", "team-*", "+1", "+20%", "-", "-", "-"},
+		{"web", "web", "deployment", "shop", "2", "1.2.3m", "1Gi", "100m", "1Gi"},
+	}
+	lineNumbers := []int{2, 3}
+
+	tests := []struct {
+		name    string
+		updates []lib.ResourceInfo
+		want    [][]string
+	}{
+		{
+			name: "single cluster keeps the original header",
+			updates: []lib.ResourceInfo{
+				{Line: 2, Cluster: "a", Workload: "api-1", Namespace: "team-x", AlterStatus: lib.AlterStatusSuccess},
+				{Line: 2, Cluster: "a", Workload: "api-2", Namespace: "team-y", AlterStatus: lib.AlterStatusFailed},
+				{Line: 3, Workload: "web", Namespace: "shop", AlterStatus: lib.AlterStatusInvalid},
+			},
+			want: [][]string{
+				header,
+				{"api-2", "*", "deployment", "team-y", "+1", "+20%", "-", "-", "-"},
+				lines[1],
+			},
+		},
+		{
+			name: "several clusters add a cluster column",
+			updates: []lib.ResourceInfo{
+				{Line: 2, Cluster: "a", Workload: "api-1", Namespace: "team-x", AlterStatus: lib.AlterStatusSuccess},
+				{Line: 2, Cluster: "b", Workload: "api-1", Namespace: "team-x", AlterStatus: lib.AlterStatusFailed},
+				{Line: 2, Cluster: "c", Workload: "api-1", Namespace: "team-x", AlterStatus: lib.AlterStatusFailed},
+			},
+			want: [][]string{
+				append(append([]string(nil), header...), lib.ColumnCluster),
+				{"api-1", "*", "deployment", "team-x", "+1", "+20%", "-", "-", "-", "b"},
+				{"api-1", "*", "deployment", "team-x", "+1", "+20%", "-", "-", "-", "c"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "example.failed.csv")
+			written, err := WriteFailedCSV(path, header, lines, lineNumbers, tt.updates)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if written != len(tt.want)-1 {
+				t.Errorf("written = %d, want %d", written, len(tt.want)-1)
+			}
+			if got := readRetryFile(t, path); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("retry file = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFailedCSVRemovesStaleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "example.failed.csv")
+	if err := os.WriteFile(path, []byte("stale\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	updates := []lib.ResourceInfo{{Line: 2, Workload: "web", AlterStatus: lib.AlterStatusSuccess}}
+	written, err := WriteFailedCSV(path, []string{"workload"}, [][]string{{"web"}}, []int{2}, updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != 0 {
+		t.Errorf("written = %d, want 0", written)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("stale retry file was not removed: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: journal
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 21:12
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package utils
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// DefaultJournalPath returns ~/.cops/journal.jsonl
+func DefaultJournalPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".cops", "journal.jsonl")
+	}
+	return filepath.Join(home, ".cops", "journal.jsonl")
+}
+
+// NewRunID returns a sortable id for an alter run, e.g. 20261018-211530-3f9a1c
+func NewRunID() string {
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// CurrentOperator returns the name of the user running cops
+func CurrentOperator() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
+
+// AppendJournal appends the records of a run to the journal, creating it when needed
+func AppendJournal(path string, records []lib.JournalRecord) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("error creating journal directory: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening journal %s: %v", path, err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		_, _ = writer.Write(append(data, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("error writing journal %s: %v", path, err)
+	}
+	return file.Sync()
+}
+
+// ReadJournal reads every record of the journal, a missing journal has no records
+func ReadJournal(path string) ([]lib.JournalRecord, error) {
+	var records []lib.JournalRecord
+	err := readJSONLines(path, func(record lib.JournalRecord) {
+		records = append(records, record)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading journal %s: %v", path, err)
+	}
+	return records, nil
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Einic/cops/lib"
+)
+
+func TestReadJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	records, err := ReadJournal(path)
+	if err != nil || len(records) != 0 {
+		t.Fatalf("ReadJournal() of a missing journal = %v, %v, want no records", records, err)
+	}
+
+	written := []lib.JournalRecord{
+		{RunID: "run-1", Outcome: lib.AlterStatusSuccess, ExitCode: lib.ExitSuccess},
+		{RunID: "run-2", Outcome: lib.AlterStatusInvalid, ExitCode: lib.ExitValidation},
+	}
+	if err := AppendJournal(path, written); err != nil {
+		t.Fatal(err)
+	}
+
+	// A line cut off by a crash is skipped
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(`{"runId":"run-3","outc`); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	records, err = ReadJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(written) {
+		t.Fatalf("ReadJournal() = %d records, want %d", len(records), len(written))
+	}
+	for i, record := range records {
+		if record.RunID != written[i].RunID || record.ExitCode != written[i].ExitCode {
+			t.Errorf("record %d = %s exit %d, want %s exit %d", i, record.RunID, record.ExitCode, written[i].RunID, written[i].ExitCode)
+		}
+	}
+}
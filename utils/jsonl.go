@@ -0,0 +1,42 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: jsonl
+ * @Version: 1.0.0
+ * @Date: 2026/10/19 01:05
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+)
+
+// readJSONLines decodes every line of a JSONL file and passes it to handle. A missing file has no lines,
+// a line cut off by a crash is skipped.
+func readJSONLines[T any](path string, handle func(T)) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var value T
+		if err := json.Unmarshal(scanner.Bytes(), &value); err != nil {
+			continue
+		}
+		handle(value)
+	}
+	return scanner.Err()
+}
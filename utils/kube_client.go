@@ -0,0 +1,93 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: kube_client
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 10:12
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package utils
+
+import (
+	"fmt"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"time"
+)
+
+// InClusterName is the cluster name shown when cops runs with the in-cluster config
+const InClusterName = "in-cluster"
+
+// probeTimeout bounds the request that checks the API server can be reached
+const probeTimeout = 10 * time.Second
+
+// NewKubeClientConfig builds a client config using the standard kubeconfig loading rules.
+// An explicit kubeconfig path wins over $KUBECONFIG and ~/.kube/config, and when no
+// kubeconfig can be found at all the in-cluster service account config is used instead.
+func NewKubeClientConfig(kubeconfig, kubeContext, namespace string) clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: kubeContext,
+		Context:        clientcmdapi.Context{Namespace: namespace},
+	}
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+}
+
+// KubeCluster is a connected cluster together with the default namespace of its context.
+type KubeCluster struct {
+	Name      string
+	Clientset *kubernetes.Clientset
+	Namespace string
+}
+
+// NewKubeCluster creates a clientset for the selected context. An empty context selects
+// the current context of the kubeconfig, or the in-cluster config. The API server is asked
+// for its version, so that an unreachable cluster is reported as a connection error.
+func NewKubeCluster(kubeconfig, kubeContext, namespace string) (*KubeCluster, error) {
+	clientConfig := NewKubeClientConfig(kubeconfig, kubeContext, namespace)
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error building kubeconfig: %v", err)
+	}
+
+	defaultNamespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("error resolving namespace: %v", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating clientset: %v", err)
+	}
+
+	probeConfig := rest.CopyConfig(config)
+	probeConfig.Timeout = probeTimeout
+	probe, err := discovery.NewDiscoveryClientForConfig(probeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating discovery client: %v", err)
+	}
+	if _, err := probe.ServerVersion(); err != nil {
+		return nil, fmt.Errorf("error connecting to the API server %s: %v", config.Host, err)
+	}
+
+	name := kubeContext
+	if name == "" {
+		if rawConfig, err := clientConfig.RawConfig(); err == nil && rawConfig.CurrentContext != "" {
+			name = rawConfig.CurrentContext
+		} else {
+			name = InClusterName
+		}
+	}
+
+	return &KubeCluster{Name: name, Clientset: clientset, Namespace: defaultNamespace}, nil
+}
@@ -0,0 +1,145 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: preflight
+ * @Version: 1.0.0
+ * @Date: 2026/10/19 11:55
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/Einic/cops/lib"
+	AlterResource "github.com/Einic/cops/resources"
+)
+
+// PermissionTarget holds the checks of the rows of one namespace, an empty namespace is the whole cluster
+type PermissionTarget struct {
+	Namespace string
+	Checks    []lib.PermissionCheck
+}
+
+// PermissionTargets derives the actions the rows need per namespace, in the order in which the namespaces first appear
+func PermissionTargets(rows []lib.ChangeRow, defaultNamespace string, namespaces []string) []PermissionTarget {
+	var targets []PermissionTarget
+	index := make(map[string]int)
+
+	add := func(namespace string, check lib.PermissionCheck) {
+		i, ok := index[namespace]
+		if !ok {
+			i = len(targets)
+			index[namespace] = i
+			targets = append(targets, PermissionTarget{Namespace: namespace})
+		}
+		for j, existing := range targets[i].Checks {
+			if existing.Name() == check.Name() {
+				targets[i].Checks[j].Required = existing.Required || check.Required
+				return
+			}
+		}
+		targets[i].Checks = append(targets[i].Checks, check)
+	}
+
+	for _, row := range rows {
+		var resource string
+		switch row.WorkType {
+		case "deployment":
+			resource = "deployments"
+		case "statefulset":
+			resource = "statefulsets"
+		default:
+			continue
+		}
+
+		namespace := row.Namespace
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+		// A namespace glob is checked in every matching namespace
+		matched := []string{namespace}
+		namespacePattern := IsPattern(namespace)
+		if namespacePattern {
+			matched = matchNames(namespaces, namespace)
+		}
+
+		// Listing the workloads is only required to match them by glob or label selector, the pods,
+		// events and ResourceQuotas only serve the QoS column, the change events and the quota warning
+		workloadPattern := namespacePattern || IsLabelSelector(row.Workload) || IsPattern(row.Workload)
+		for _, namespace := range matched {
+			add(namespace, lib.PermissionCheck{Group: "apps", Resource: resource, Verb: "get", Required: true})
+			add(namespace, lib.PermissionCheck{Group: "apps", Resource: resource, Verb: "list", Required: workloadPattern})
+			add(namespace, lib.PermissionCheck{Group: "apps", Resource: resource, Verb: "update", Required: true})
+			if resource == "deployments" {
+				add(namespace, lib.PermissionCheck{Group: "apps", Resource: "replicasets", Verb: "list"})
+			}
+			add(namespace, lib.PermissionCheck{Resource: "pods", Verb: "list"})
+			add(namespace, lib.PermissionCheck{Resource: "events", Verb: "create"})
+			add(namespace, lib.PermissionCheck{Resource: "resourcequotas", Verb: "list"})
+		}
+	}
+
+	return targets
+}
+
+// CheckClusterPermissions reviews every permission target of a cluster
+func CheckClusterPermissions(ctx context.Context, cluster *KubeCluster, rows []lib.ChangeRow) ([]lib.Permission, error) {
+	var permissions []lib.Permission
+	var namespaces []string
+
+	// Namespace globs need the namespaces to be listed, which is checked for the whole cluster first
+	for _, row := range rows {
+		if row.Namespace == "" || !IsPattern(row.Namespace) {
+			continue
+		}
+		reviewed, err := AlterResource.CheckPermissions(ctx, cluster.Clientset, "", []lib.PermissionCheck{{Resource: "namespaces", Verb: "list", Required: true}})
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, reviewed...)
+		if reviewed[0].Allowed {
+			if namespaces, err = AlterResource.ListNamespaceNames(ctx, cluster.Clientset); err != nil {
+				return nil, fmt.Errorf("error listing namespaces: %v", err)
+			}
+		}
+		break
+	}
+
+	for _, target := range PermissionTargets(rows, cluster.Namespace, namespaces) {
+		reviewed, err := AlterResource.CheckPermissions(ctx, cluster.Clientset, target.Namespace, target.Checks)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, reviewed...)
+	}
+
+	for i := range permissions {
+		permissions[i].Cluster = cluster.Name
+	}
+	return permissions, nil
+}
+
+// LabelPermissionChecks lists the actions of the labels command, patching is only required to fix
+func LabelPermissionChecks(fix bool) []lib.PermissionCheck {
+	return []lib.PermissionCheck{
+		{Group: "apps", Resource: "deployments", Verb: "list", Required: true},
+		{Group: "apps", Resource: "deployments", Verb: "patch", Required: fix},
+		{Group: "apps", Resource: "statefulsets", Verb: "list", Required: true},
+		{Group: "apps", Resource: "statefulsets", Verb: "patch", Required: fix},
+	}
+}
+
+// MissingPermissions returns the denied permissions that are required
+func MissingPermissions(permissions []lib.Permission) []lib.Permission {
+	var missing []lib.Permission
+	for _, permission := range permissions {
+		if !permission.Allowed && permission.Check.Required {
+			missing = append(missing, permission)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Einic/cops/lib"
+)
+
+// targetChecks flattens the targets to namespace -> check name -> required
+func targetChecks(targets []PermissionTarget) map[string]map[string]bool {
+	checks := make(map[string]map[string]bool)
+	for _, target := range targets {
+		checks[target.Namespace] = make(map[string]bool)
+		for _, check := range target.Checks {
+			checks[target.Namespace][check.Name()] = check.Required
+		}
+	}
+	return checks
+}
+
+// workloadChecks returns the checks of a row on resource, listing it is required for patterns
+func workloadChecks(resource string, list bool) map[string]bool {
+	checks := map[string]bool{
+		"get " + resource:     true,
+		"list " + resource:    list,
+		"update " + resource:  true,
+		"list pods":           false,
+		"create events":       false,
+		"list resourcequotas": false,
+	}
+	if resource == "deployments" {
+		checks["list replicasets"] = false
+	}
+	return checks
+}
+
+func TestPermissionTargets(t *testing.T) {
+	namespaces := []string{"team-a", "team-b", "shop"}
+
+	tests := []struct {
+		name           string
+		rows           []lib.ChangeRow
+		wantNamespaces []string
+		want           map[string]map[string]bool
+	}{
+		{
+			name:           "named workload in the default namespace",
+			rows:           []lib.ChangeRow{{Workload: "web", WorkType: "statefulset"}},
+			wantNamespaces: []string{"default"},
+			want:           map[string]map[string]bool{"default": workloadChecks("statefulsets", false)},
+		},
+		{
+			name:           "label selector needs list",
+			rows:           []lib.ChangeRow{{Workload: "app=api", WorkType: "deployment", Namespace: "shop"}},
+			wantNamespaces: []string{"shop"},
+			want:           map[string]map[string]bool{"shop": workloadChecks("deployments", true)},
+		},
+		{
+			name:           "namespace glob is checked in every matched namespace",
+			rows:           []lib.ChangeRow{{Workload: "api", WorkType: "statefulset", Namespace: "team-*"}},
+			wantNamespaces: []string{"team-a", "team-b"},
+			want: map[string]map[string]bool{
+				"team-a": workloadChecks("statefulsets", true),
+				"team-b": workloadChecks("statefulsets", true),
+			},
+		},
+		{
+			name: "list is required once any row of the namespace needs it",
+			rows: []lib.ChangeRow{
+				{Workload: "web", WorkType: "deployment", Namespace: "shop"},
+				{Workload: "api-*", WorkType: "deployment", Namespace: "shop"},
+			},
+			wantNamespaces: []string{"shop"},
+			want:           map[string]map[string]bool{"shop": workloadChecks("deployments", true)},
+		},
+		{
+			name:           "unsupported worktype needs nothing",
+			rows:           []lib.ChangeRow{{Workload: "web", WorkType: "daemonset", Namespace: "shop"}},
+			wantNamespaces: nil,
+			want:           map[string]map[string]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets := PermissionTargets(tt.rows, "default", namespaces)
+
+			var gotNamespaces []string
+			for _, target := range targets {
+				gotNamespaces = append(gotNamespaces, target.Namespace)
+			}
+			if !reflect.DeepEqual(gotNamespaces, tt.wantNamespaces) {
+				t.Fatalf("namespaces = %v, want %v", gotNamespaces, tt.wantNamespaces)
+			}
+			if checks := targetChecks(targets); !reflect.DeepEqual(checks, tt.want) {
+				t.Errorf("checks = %v, want %v", checks, tt.want)
+			}
+		})
+	}
+}
@@ -20,11 +20,14 @@ import (
 	AlterResource "github.com/Einic/cops/resources"
 	"github.com/Einic/cops/zaplog"
 	"io"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"math"
 	"os"
+	"strconv"
 	"strings"
-	"unicode"
 )
 
 func PrintVersionAndMD5() {
@@ -66,26 +69,161 @@ func printDefaultContent() {
 ***********************************************`)
 }
 
-// ParseCSV parses a CSV file and returns its content as a 2D slice.
-func ParseCSV(csvPath string) ([][]string, error) {
+// ParseCSV parses a CSV file and returns its header as written, its content as a 2D slice and the line number of
+// every record. Records with a wrong number of fields are kept, so that they are reported with their line.
+func ParseCSV(csvPath string) ([]string, [][]string, []int, error) {
 	file, err := os.Open(csvPath)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	defer file.Close()
 
 	reader := csv.NewReader(file)
-	_, err = reader.Read() // Read and discard the header line
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read() // Read the header line to locate the columns
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
-	lines, err := reader.ReadAll()
-	if err != nil {
-		return nil, err
+	var lines [][]string
+	var lineNumbers []int
+	for {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		lineNumber, _ := reader.FieldPos(0)
+		lines = append(lines, line)
+		lineNumbers = append(lineNumbers, lineNumber)
+	}
+
+	return header, lines, lineNumbers, nil
+}
+
+// ValidateHeader checks that all required columns are present in the CSV header.
+func ValidateHeader(header []string) error {
+	for _, column := range lib.RequiredColumns {
+		if columnIndex(header, column) < 0 {
+			return fmt.Errorf("missing column %q in CSV header", column)
+		}
+	}
+	return nil
+}
+
+// ParseChangeRow maps a CSV line onto a ChangeRow using the column positions of the header.
+// The namespace and cluster columns may be empty, they are defaulted from the kube context.
+func ParseChangeRow(header, line []string) (lib.ChangeRow, error) {
+	var row lib.ChangeRow
+
+	if len(line) != len(header) {
+		return row, fmt.Errorf("invalid CSV format, expected %d fields per line, got %d", len(header), len(line))
+	}
+
+	field := func(column string) string {
+		if i := columnIndex(header, column); i >= 0 {
+			return strings.TrimSpace(line[i])
+		}
+		return ""
+	}
+
+	row = lib.ChangeRow{
+		Cluster:        field(lib.ColumnCluster),
+		Workload:       field(lib.ColumnWorkload),
+		ContainerName:  field(lib.ColumnContainersName),
+		ContainerKind:  strings.ToLower(field(lib.ColumnContainerKind)),
+		WorkType:       field(lib.ColumnWorkType),
+		Namespace:      field(lib.ColumnNamespace),
+		LimitsCPU:      field(lib.ColumnLimitsCPU),
+		LimitsMemory:   field(lib.ColumnLimitsMemory),
+		RequestsCPU:    field(lib.ColumnRequestsCPU),
+		RequestsMemory: field(lib.ColumnRequestsMemory),
+	}
+
+	if !ValidateFields([]string{row.Workload, row.ContainerName, row.WorkType, field(lib.ColumnReplicas), row.LimitsCPU, row.LimitsMemory, row.RequestsCPU, row.RequestsMemory}) {
+		return row, fmt.Errorf("empty field found in CSV")
+	}
+
+	if !IsCPUValue(row.LimitsCPU) || !IsCPUValue(row.RequestsCPU) {
+		return row, fmt.Errorf("invalid CPU limit/request %s/%s, it should be in milli-units (suffix 'm'), relative ('+20%%', 'x2', '+100m') or removed ('-', 'none')", row.LimitsCPU, row.RequestsCPU)
+	}
+	if !IsMemoryValue(row.LimitsMemory) || !IsMemoryValue(row.RequestsMemory) {
+		return row, fmt.Errorf("invalid memory limit/request %s/%s, it should be in Mebibytes (suffix 'Mi'), relative ('+20%%', 'x2', '-256Mi') or removed ('-', 'none')", row.LimitsMemory, row.RequestsMemory)
+	}
+
+	// Other named resources are optional, an empty value leaves the resource unchanged
+	for i, name := range header {
+		column := normalizeColumn(name)
+		value := strings.TrimSpace(line[i])
+		if value == "" {
+			continue
+		}
+
+		switch {
+		case column == lib.ColumnLimitsCPU, column == lib.ColumnLimitsMemory, column == lib.ColumnRequestsCPU, column == lib.ColumnRequestsMemory:
+			continue
+		case strings.HasPrefix(column, lib.ColumnLimitsPrefix):
+			if row.OtherLimits == nil {
+				row.OtherLimits = make(map[string]string)
+			}
+			row.OtherLimits[strings.TrimPrefix(column, lib.ColumnLimitsPrefix)] = value
+		case strings.HasPrefix(column, lib.ColumnRequestsPrefix):
+			if row.OtherRequests == nil {
+				row.OtherRequests = make(map[string]string)
+			}
+			row.OtherRequests[strings.TrimPrefix(column, lib.ColumnRequestsPrefix)] = value
+		default:
+			continue
+		}
+
+		if !IsQuantityValue(value) {
+			return row, fmt.Errorf("%s should be a quantity or relative to the current value ('+20%%', 'x2', '+1Gi'), got %q", column, value)
+		}
 	}
 
-	return lines, nil
+	switch row.ContainerKind {
+	case "":
+		row.ContainerKind = lib.ContainerKindContainer
+	case lib.ContainerKindContainer, lib.ContainerKindInit, lib.ContainerKindSidecar:
+	default:
+		return row, fmt.Errorf("container_kind should be one of %s, %s or %s, got %q", lib.ContainerKindContainer, lib.ContainerKindInit, lib.ContainerKindSidecar, row.ContainerKind)
+	}
+
+	// The target QoS class is optional and matched case-insensitively
+	if targetQoS := field(lib.ColumnTargetQoS); targetQoS != "" {
+		for _, qosClass := range []corev1.PodQOSClass{corev1.PodQOSGuaranteed, corev1.PodQOSBurstable, corev1.PodQOSBestEffort} {
+			if strings.EqualFold(targetQoS, string(qosClass)) {
+				row.TargetQoS = string(qosClass)
+			}
+		}
+		if row.TargetQoS == "" {
+			return row, fmt.Errorf("target_qos should be one of %s, %s or %s, got %q", corev1.PodQOSGuaranteed, corev1.PodQOSBurstable, corev1.PodQOSBestEffort, targetQoS)
+		}
+	}
+
+	row.Replicas = field(lib.ColumnReplicas)
+	if !IsReplicasValue(row.Replicas) {
+		return row, fmt.Errorf("replicas should be an integer or relative to the current replicas, got %q", row.Replicas)
+	}
+
+	return row, nil
+}
+
+// columnIndex returns the position of a column in the header, or -1 if it is absent.
+func columnIndex(header []string, column string) int {
+	for i, name := range header {
+		if normalizeColumn(name) == column {
+			return i
+		}
+	}
+	return -1
+}
+
+// normalizeColumn matches header names regardless of case and surrounding spaces
+func normalizeColumn(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
 }
 
 // ValidateFields checks if any field in a CSV line is empty.
@@ -98,44 +236,88 @@ func ValidateFields(line []string) bool {
 	return true
 }
 
-// IsMilliCPU checks if a CPU limit/request value is in milli-units.
+// IsMilliCPU checks if a CPU limit/request value is a valid quantity in milli-units.
 func IsMilliCPU(cpu string) bool {
-	if strings.HasSuffix(cpu, "m") && len(cpu) > 1 && unicode.IsDigit(rune(cpu[len(cpu)-2])) {
+	return strings.HasSuffix(cpu, "m") && isQuantity(cpu)
+}
+
+// IsMegaMemory checks if a memory limit/request value is a valid quantity in Mebibytes.
+func IsMegaMemory(memory string) bool {
+	return strings.HasSuffix(memory, "Mi") && isQuantity(memory)
+}
+
+// isQuantity checks if a value parses as a non-negative quantity
+func isQuantity(value string) bool {
+	quantity, err := resource.ParseQuantity(value)
+	return err == nil && quantity.Sign() >= 0
+}
+
+// IsCPUValue checks if a CPU value is in milli-units, relative to the current value ('+20%', 'x2', '+100m')
+// or removes the CPU limit/request ('-', 'none').
+func IsCPUValue(cpu string) bool {
+	return IsMilliCPU(cpu) || AlterResource.IsRelativeValue(cpu, "m") || AlterResource.IsRemoveValue(cpu)
+}
+
+// IsMemoryValue checks if a memory value is in Mebibytes, relative to the current value ('+20%', 'x2', '-256Mi')
+// or removes the memory limit/request ('-', 'none').
+func IsMemoryValue(memory string) bool {
+	return IsMegaMemory(memory) || AlterResource.IsRelativeValue(memory, "Mi") || AlterResource.IsRemoveValue(memory)
+}
+
+// IsQuantityValue checks if the value of another named resource is a quantity, relative to the current value
+// ('+20%', 'x2', '+1Gi') or removes the resource ('-', 'none').
+func IsQuantityValue(value string) bool {
+	if AlterResource.IsRemoveValue(value) {
 		return true
 	}
-	return false
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		if strings.HasSuffix(value, "%") {
+			return AlterResource.IsRelativeValue(value, "")
+		}
+		value = value[1:]
+	} else if strings.HasPrefix(value, "x") {
+		return AlterResource.IsRelativeValue(value, "")
+	}
+	_, err := resource.ParseQuantity(value)
+	return err == nil
 }
 
-// IsMegaMemory checks if a memory limit/request value is in Mebibytes.
-func IsMegaMemory(memory string) bool {
-	if strings.HasSuffix(memory, "Mi") && len(memory) > 2 && unicode.IsDigit(rune(memory[len(memory)-3])) {
+// IsReplicasValue checks if a replicas value is an integer or relative to the current replicas ('+1', '-50%', 'x2').
+func IsReplicasValue(replicas string) bool {
+	if n, err := strconv.Atoi(replicas); err == nil && n <= math.MaxInt32 && !strings.HasPrefix(replicas, "+") && !strings.HasPrefix(replicas, "-") {
 		return true
 	}
-	return false
+	return AlterResource.IsRelativeValue(replicas, "")
 }
 
-// UpdateWorkload updates the specified workload based on its type.
-func UpdateWorkload(clientset *kubernetes.Clientset, worktype, namespace, workload, containersName, limitsCPU, limitsMemory, requestsCPU, requestsMemory string, replicas int, logger zaplog.Logger) (lib.ResourceInfo, error) {
-	var update lib.ResourceInfo
+// UpdateWorkload updates the specified workload based on its type, applying all its container changes at once.
+func UpdateWorkload(ctx context.Context, clientset *kubernetes.Clientset, change lib.WorkloadChange, logger zaplog.Logger) ([]lib.ResourceInfo, error) {
+	var updates []lib.ResourceInfo
 
-	switch worktype {
+	switch change.WorkType {
 	case "deployment":
-		deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.TODO(), workload, metav1.GetOptions{})
+		deployment, err := clientset.AppsV1().Deployments(change.Namespace).Get(ctx, change.Workload, metav1.GetOptions{})
 		if err != nil {
-			return update, fmt.Errorf("error getting deployment %s in namespace %s: %v", workload, namespace, err)
+			return updates, fmt.Errorf("error getting deployment %s in namespace %s: %v", change.Workload, change.Namespace, err)
+		}
+		updates, err = AlterResource.UpdateDeployment(ctx, clientset, deployment, change, logger)
+		if err != nil {
+			return updates, err
 		}
-		update = AlterResource.UpdateDeployment(clientset, deployment, replicas, containersName, limitsCPU, limitsMemory, requestsCPU, requestsMemory, namespace, logger)
 
 	case "statefulset":
-		statefulSet, err := clientset.AppsV1().StatefulSets(namespace).Get(context.TODO(), workload, metav1.GetOptions{})
+		statefulSet, err := clientset.AppsV1().StatefulSets(change.Namespace).Get(ctx, change.Workload, metav1.GetOptions{})
+		if err != nil {
+			return updates, fmt.Errorf("error getting statefulset %s in namespace %s: %v", change.Workload, change.Namespace, err)
+		}
+		updates, err = AlterResource.UpdateStatefulSet(ctx, clientset, statefulSet, change, logger)
 		if err != nil {
-			return update, fmt.Errorf("error getting statefulset %s in namespace %s: %v", workload, namespace, err)
+			return updates, err
 		}
-		update = AlterResource.UpdateStatefulSet(clientset, statefulSet, replicas, containersName, limitsCPU, limitsMemory, requestsCPU, requestsMemory, namespace, logger)
 
 	default:
-		return update, fmt.Errorf("unsupported worktype: %s", worktype)
+		return updates, fmt.Errorf("unsupported worktype: %s", change.WorkType)
 	}
 
-	return update, nil
+	return updates, nil
 }
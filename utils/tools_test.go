@@ -0,0 +1,50 @@
+package utils
+
+import "testing"
+
+func TestParseChangeRowValues(t *testing.T) {
+	header := []string{"workload", "containers_name", "worktype", "namespace", "replicas", "limits_cpu", "limits_memory", "requests_cpu", "requests_memory"}
+
+	tests := []struct {
+		name    string
+		line    []string
+		wantErr bool
+	}{
+		{"absolute", []string{"api", "api", "deployment", "shop", "2", "500m", "512Mi", "250m", "256Mi"}, false},
+		{"relative and removed", []string{"api", "*", "deployment", "shop", "+1", "+20%", "x2", "-", "none"}, false},
+		{"signed amounts", []string{"api", "api", "deployment", "shop", "-1", "+100m", "-256Mi", "-50m", "+64Mi"}, false},
+		{"decimal milli cpu", []string{"api", "api", "deployment", "shop", "2", "1.5m", "512Mi", "250m", "256Mi"}, false},
+		{"malformed cpu", []string{"api", "api", "deployment", "shop", "2", "1.2.3m", "512Mi", "250m", "256Mi"}, true},
+		{"malformed memory", []string{"api", "api", "deployment", "shop", "2", "500m", "abc1Mi", "250m", "256Mi"}, true},
+		{"cpu without unit", []string{"api", "api", "deployment", "shop", "2", "1", "512Mi", "250m", "256Mi"}, true},
+		{"memory in Gi", []string{"api", "api", "deployment", "shop", "2", "500m", "1Gi", "250m", "256Mi"}, true},
+		{"empty field", []string{"api", "api", "deployment", "shop", "2", "", "512Mi", "250m", "256Mi"}, true},
+		{"wrong field count", []string{"api", "api"}, true},
+		{"infinite replicas factor", []string{"api", "api", "deployment", "shop", "xInf", "500m", "512Mi", "250m", "256Mi"}, true},
+		{"replicas above int32", []string{"api", "api", "deployment", "shop", "2147483648", "500m", "512Mi", "250m", "256Mi"}, true},
+		{"exponent cpu factor", []string{"api", "api", "deployment", "shop", "2", "x1e20", "512Mi", "250m", "256Mi"}, true},
+		{"signed memory percent", []string{"api", "api", "deployment", "shop", "2", "500m", "+-5%", "250m", "256Mi"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseChangeRow(header, tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseChangeRow(%v) error = %v, wantErr %v", tt.line, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseChangeRowHeaderCase(t *testing.T) {
+	header := []string{" Workload", "Containers_Name", "WorkType", "Namespace", "Replicas", "Limits_CPU", "Limits_Memory", "Requests_CPU", "Requests_Memory", "Limits_Ephemeral-Storage"}
+	line := []string{"api", "api", "deployment", "shop", "2", "500m", "512Mi", "250m", "256Mi", "1Gi"}
+
+	row, err := ParseChangeRow(header, line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.Workload != "api" || row.LimitsCPU != "500m" || row.OtherLimits["ephemeral-storage"] != "1Gi" {
+		t.Errorf("ParseChangeRow with a mixed-case header = %+v", row)
+	}
+}
@@ -0,0 +1,52 @@
+/**
+ * @Author: Einic <einicyeo AT gmail.com>
+ * @Description:
+ * @File: log_buffer.go
+ * @Version: 1.0.0
+ * @Date: 2026/10/18 18:30
+ * @BLOG:  https://www.infvie.com
+ * @Project home page:
+ *     @https://github.com/Einic/EnvoyinStack
+ */
+
+package zaplog
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// maxExcerptLines is the number of most recent log lines kept for the run report
+const maxExcerptLines = 500
+
+// ansiColor matches the color codes of the console level encoder
+var ansiColor = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// lineBuffer keeps the most recent log lines of the run in memory
+type lineBuffer struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+var runLog = &lineBuffer{}
+
+func (b *lineBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		b.lines = append(b.lines, ansiColor.ReplaceAllString(line, ""))
+	}
+	if len(b.lines) > maxExcerptLines {
+		b.lines = b.lines[len(b.lines)-maxExcerptLines:]
+	}
+	return len(p), nil
+}
+
+// LogExcerpt returns the most recent log lines of the run without color codes
+func LogExcerpt() []string {
+	runLog.mu.Lock()
+	defer runLog.mu.Unlock()
+	return append([]string(nil), runLog.lines...)
+}
@@ -135,7 +135,9 @@ func getLogWriter() zapcore.WriteSyncer {
 		MaxAge:     1,
 		Compress:   true,
 	}
-	ws := io.MultiWriter(lumberJackLogger, os.Stdout)
+	// The console log goes to stderr, so stdout only carries the result report.
+	// The run log is also kept in memory for the excerpt of the HTML report
+	ws := io.MultiWriter(lumberJackLogger, os.Stderr, runLog)
 	return zapcore.AddSync(ws)
 }
 